@@ -0,0 +1,22 @@
+package gr_worker
+
+import "fmt"
+
+// PanicError is returned by ExecuteTask when the task function panics.
+// It holds the recovered value and the stack trace of the panicking goroutine.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// Unwrap returns the recovered value if the task panicked with an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
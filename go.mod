@@ -2,4 +2,4 @@ module github.com/vd09/gr_worker
 
 go 1.21.5
 
-require github.com/vd09/gr-variable v0.0.0-20240505213543-579df24f059a // indirect
+require github.com/vd09/gr-variable v0.0.0-20240505213543-579df24f059a
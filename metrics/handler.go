@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vd09/gr_worker/worker_pool"
+)
+
+const (
+	ContentType   = "text/plain; version=0.0.4; charset=utf-8"
+	MetricsPrefix = "gr_worker_pool_"
+)
+
+var (
+	ErrNilPool       = errors.New("worker pool can't be nil")
+	ErrEmptyPoolName = errors.New("pool name can't be empty")
+	ErrDuplicatePool = errors.New("pool name is already registered")
+)
+
+// Pool is the part of a worker pool read by the handler, every worker_pool.WorkerPool implements it.
+type Pool interface {
+	Stats() worker_pool.Stats
+	QueueWaitHistogram() worker_pool.Histogram
+	ExecutionHistogram() worker_pool.Histogram
+}
+
+// Handler exposes the metrics of the registered pools in the Prometheus text format,
+// every sample is labelled with the name of its pool.
+type Handler struct {
+	mutex sync.RWMutex
+	pools map[string]Pool
+}
+
+func NewHandler() *Handler {
+	return &Handler{pools: make(map[string]Pool)}
+}
+
+// Register adds a pool to the handler under the given name.
+func (h *Handler) Register(name string, pool Pool) error {
+	if pool == nil {
+		return ErrNilPool
+	}
+	if name == "" {
+		return ErrEmptyPoolName
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.pools[name]; ok {
+		return ErrDuplicatePool
+	}
+	h.pools[name] = pool
+	return nil
+}
+
+// Unregister removes a pool from the handler, it returns false if no pool is registered under the name.
+func (h *Handler) Unregister(name string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.pools[name]; !ok {
+		return false
+	}
+	delete(h.pools, name)
+	return true
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_ = h.Write(w)
+}
+
+// poolSnapshot is read once per pool, so all the metrics of a pool come from the same snapshot.
+type poolSnapshot struct {
+	name      string
+	stats     worker_pool.Stats
+	queueWait worker_pool.Histogram
+	execution worker_pool.Histogram
+}
+
+// Write writes the metrics of all the registered pools, ordered by pool name.
+func (h *Handler) Write(w io.Writer) error {
+	snapshots := h.snapshots()
+	bw := bufio.NewWriter(w)
+
+	for _, family := range gaugeFamilies {
+		writeHeader(bw, family.name, family.help, "gauge")
+		for _, snapshot := range snapshots {
+			writeSample(bw, family.name, snapshot.name, "", float64(family.value(&snapshot.stats)))
+		}
+	}
+	for _, family := range counterFamilies {
+		writeHeader(bw, family.name, family.help, "counter")
+		for _, snapshot := range snapshots {
+			writeSample(bw, family.name, snapshot.name, "", float64(family.value(&snapshot.stats)))
+		}
+	}
+
+	writeHeader(bw, MetricsPrefix+"queue_wait_seconds", "Time spent by the tasks in the queue before their execution.", "histogram")
+	for _, snapshot := range snapshots {
+		writeHistogram(bw, MetricsPrefix+"queue_wait_seconds", snapshot.name, snapshot.queueWait)
+	}
+	writeHeader(bw, MetricsPrefix+"execution_seconds", "Execution time of the tasks, including the failed attempts.", "histogram")
+	for _, snapshot := range snapshots {
+		writeHistogram(bw, MetricsPrefix+"execution_seconds", snapshot.name, snapshot.execution)
+	}
+	return bw.Flush()
+}
+
+func (h *Handler) snapshots() []*poolSnapshot {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	snapshots := make([]*poolSnapshot, 0, len(h.pools))
+	for name, pool := range h.pools {
+		snapshots = append(snapshots, &poolSnapshot{
+			name:      name,
+			stats:     pool.Stats(),
+			queueWait: pool.QueueWaitHistogram(),
+			execution: pool.ExecutionHistogram(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].name < snapshots[j].name })
+	return snapshots
+}
+
+type statsFamily[T int | uint64] struct {
+	name  string
+	help  string
+	value func(stats *worker_pool.Stats) T
+}
+
+var gaugeFamilies = []statsFamily[int]{
+	{MetricsPrefix + "active_workers", "Number of running workers.", func(s *worker_pool.Stats) int { return s.ActiveWorkers }},
+	{MetricsPrefix + "idle_workers", "Number of workers waiting for a task.", func(s *worker_pool.Stats) int { return s.IdleWorkers }},
+	{MetricsPrefix + "busy_workers", "Number of workers executing a task.", func(s *worker_pool.Stats) int { return s.BusyWorkers }},
+	{MetricsPrefix + "concurrency_limit", "Highest number of tasks executed concurrently.", func(s *worker_pool.Stats) int { return s.ConcurrencyLimit }},
+	{MetricsPrefix + "paused", "Whether the pool is paused.", func(s *worker_pool.Stats) int { return boolToInt(s.Paused) }},
+	{MetricsPrefix + "queued_tasks", "Number of tasks waiting in the queue.", func(s *worker_pool.Stats) int { return s.QueuedTasks }},
+	{MetricsPrefix + "scheduled_tasks", "Number of scheduled tasks which are not due yet.", func(s *worker_pool.Stats) int { return s.ScheduledTasks }},
+}
+
+var counterFamilies = []statsFamily[uint64]{
+	{MetricsPrefix + "tasks_submitted_total", "Tasks accepted by the pool.", func(s *worker_pool.Stats) uint64 { return s.SubmittedTasks }},
+	{MetricsPrefix + "tasks_completed_total", "Tasks finished without error.", func(s *worker_pool.Stats) uint64 { return s.CompletedTasks }},
+	{MetricsPrefix + "tasks_failed_total", "Tasks finished with an error.", func(s *worker_pool.Stats) uint64 { return s.FailedTasks }},
+	{MetricsPrefix + "tasks_panicked_total", "Tasks finished with a panic.", func(s *worker_pool.Stats) uint64 { return s.PanickedTasks }},
+	{MetricsPrefix + "tasks_rejected_total", "Tasks rejected by the pool.", func(s *worker_pool.Stats) uint64 { return s.RejectedTasks }},
+	{MetricsPrefix + "tasks_retried_total", "Failed attempts which were retried.", func(s *worker_pool.Stats) uint64 { return s.RetriedTasks }},
+	{MetricsPrefix + "tasks_dropped_total", "Tasks dropped while stopping the pool.", func(s *worker_pool.Stats) uint64 { return s.DroppedTasks }},
+}
+
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func writeHeader(w *bufio.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeSample(w *bufio.Writer, name, pool, extraLabels string, value float64) {
+	fmt.Fprintf(w, "%s{pool=\"%s\"%s} %s\n", name, escapeLabelValue(pool), extraLabels, formatFloat(value))
+}
+
+// writeHistogram converts the buckets of the pool histogram into the cumulative buckets of Prometheus.
+func writeHistogram(w *bufio.Writer, name, pool string, histogram worker_pool.Histogram) {
+	var cumulative uint64
+	for i, bound := range histogram.Bounds {
+		cumulative += histogram.Counts[i]
+		writeSample(w, name+"_bucket", pool, `,le="`+formatFloat(bound.Seconds())+`"`, float64(cumulative))
+	}
+	writeSample(w, name+"_bucket", pool, `,le="+Inf"`, float64(histogram.Count))
+	writeSample(w, name+"_sum", pool, "", histogram.Sum.Seconds())
+	writeSample(w, name+"_count", pool, "", float64(histogram.Count))
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
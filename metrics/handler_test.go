@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/worker_pool"
+)
+
+type fakePool struct {
+	stats     worker_pool.Stats
+	histogram worker_pool.Histogram
+}
+
+func (fp *fakePool) Stats() worker_pool.Stats {
+	return fp.stats
+}
+
+func (fp *fakePool) QueueWaitHistogram() worker_pool.Histogram {
+	return fp.histogram
+}
+
+func (fp *fakePool) ExecutionHistogram() worker_pool.Histogram {
+	return fp.histogram
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	handler := NewHandler()
+	_ = handler.Register("images", &fakePool{
+		stats: worker_pool.Stats{ActiveWorkers: 3, BusyWorkers: 2, QueuedTasks: 7, CompletedTasks: 10, PanickedTasks: 1},
+		histogram: worker_pool.Histogram{
+			Bounds: []time.Duration{10 * time.Millisecond, time.Second},
+			Counts: []uint64{2, 3, 1},
+			Count:  6,
+			Sum:    2500 * time.Millisecond,
+		},
+	})
+	_ = handler.Register(`mail"er`, &fakePool{})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != ContentType {
+		t.Errorf("unexpected content type, got: %q, want: %q", contentType, ContentType)
+	}
+
+	body := recorder.Body.String()
+	expectedLines := []string{
+		"# TYPE gr_worker_pool_active_workers gauge",
+		`gr_worker_pool_active_workers{pool="images"} 3`,
+		`gr_worker_pool_busy_workers{pool="images"} 2`,
+		`gr_worker_pool_queued_tasks{pool="images"} 7`,
+		"# TYPE gr_worker_pool_tasks_completed_total counter",
+		`gr_worker_pool_tasks_completed_total{pool="images"} 10`,
+		`gr_worker_pool_tasks_panicked_total{pool="images"} 1`,
+		`gr_worker_pool_tasks_completed_total{pool="mail\"er"} 0`,
+		"# TYPE gr_worker_pool_execution_seconds histogram",
+		`gr_worker_pool_execution_seconds_bucket{pool="images",le="0.01"} 2`,
+		`gr_worker_pool_execution_seconds_bucket{pool="images",le="1"} 5`,
+		`gr_worker_pool_execution_seconds_bucket{pool="images",le="+Inf"} 6`,
+		`gr_worker_pool_execution_seconds_sum{pool="images"} 2.5`,
+		`gr_worker_pool_execution_seconds_count{pool="images"} 6`,
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("metrics don't contain %q", line)
+		}
+	}
+	if strings.Count(body, "# TYPE gr_worker_pool_active_workers ") != 1 {
+		t.Error("metric family is not written once for all the pools")
+	}
+}
+
+func TestHandler_WorkerPool(t *testing.T) {
+	pool, err := worker_pool.NewWorkerPool(worker_pool.WithMaxWorkers(1))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	pool.AddTask(func() {})
+	pool.WaitAndStop()
+
+	handler := NewHandler()
+	if err := handler.Register("default", pool); err != nil {
+		t.Fatalf("error registering pool: %v", err)
+	}
+
+	var body strings.Builder
+	if err := handler.Write(&body); err != nil {
+		t.Fatalf("error writing metrics: %v", err)
+	}
+	if !strings.Contains(body.String(), `gr_worker_pool_tasks_completed_total{pool="default"} 1`) {
+		t.Errorf("completed task is not reported:\n%s", body.String())
+	}
+	if !strings.Contains(body.String(), `gr_worker_pool_queue_wait_seconds_count{pool="default"} 1`) {
+		t.Errorf("queue wait of the task is not reported:\n%s", body.String())
+	}
+}
+
+func TestHandler_Register(t *testing.T) {
+	handler := NewHandler()
+	tests := []struct {
+		name     string
+		pool     Pool
+		expected error
+	}{
+		{"default", &fakePool{}, nil},
+		{"default", &fakePool{}, ErrDuplicatePool},
+		{"", &fakePool{}, ErrEmptyPoolName},
+		{"other", nil, ErrNilPool},
+	}
+	for _, test := range tests {
+		if err := handler.Register(test.name, test.pool); err != test.expected {
+			t.Errorf("unexpected error registering %q, got: %v, want: %v", test.name, err, test.expected)
+		}
+	}
+
+	if !handler.Unregister("default") || handler.Unregister("default") {
+		t.Error("pool is not unregistered once")
+	}
+}
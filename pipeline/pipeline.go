@@ -0,0 +1,218 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vd09/gr_worker/worker_pool"
+)
+
+var (
+	ErrNoStages      = errors.New("pipeline needs at least one stage")
+	ErrNilStageFunc  = errors.New("stage function can't be nil")
+	ErrBuffer        = errors.New("stage buffer can't be less than zero")
+	ErrStarted       = errors.New("pipeline is already started")
+	ErrNotStarted    = errors.New("pipeline is not started")
+	ErrStopped       = errors.New("pipeline is stopped")
+	ErrDuplicateName = errors.New("stage name is already used")
+)
+
+// Builder collects the stages of a pipeline in processing order.
+type Builder struct {
+	stages []Stage
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Stage adds a stage with the default buffer, the options configure its worker pool.
+func (b *Builder) Stage(name string, fn StageFunc, options ...worker_pool.Option) *Builder {
+	return b.AddStage(Stage{Name: name, Func: fn, Options: options})
+}
+
+func (b *Builder) AddStage(stage Stage) *Builder {
+	b.stages = append(b.stages, stage)
+	return b
+}
+
+// Build validates the stages and creates their worker pools.
+func (b *Builder) Build() (*Pipeline, error) {
+	if len(b.stages) == 0 {
+		return nil, ErrNoStages
+	}
+
+	p := &Pipeline{done: make(chan struct{})}
+	names := make(map[string]struct{}, len(b.stages))
+	for i, config := range b.stages {
+		if config.Name == "" {
+			config.Name = fmt.Sprintf("stage-%d", i)
+		}
+		if _, ok := names[config.Name]; ok {
+			p.stopPools()
+			return nil, fmt.Errorf("pipeline stage %s: %w", config.Name, ErrDuplicateName)
+		}
+		names[config.Name] = struct{}{}
+
+		s, err := newStage(config)
+		if err != nil {
+			p.stopPools()
+			return nil, fmt.Errorf("pipeline stage %s: %w", config.Name, err)
+		}
+		p.stages = append(p.stages, s)
+	}
+	return p, nil
+}
+
+func newStage(config Stage) (*stage, error) {
+	if config.Func == nil {
+		return nil, ErrNilStageFunc
+	}
+	if config.Buffer < 0 {
+		return nil, ErrBuffer
+	}
+
+	s := &stage{
+		name:   config.Name,
+		fn:     config.Func,
+		buffer: config.Buffer,
+	}
+	if s.buffer == 0 {
+		s.buffer = DefaultBuffer
+	}
+
+	pool, err := worker_pool.NewWorkerPoolAdapter(config.Options...)
+	if err != nil {
+		return nil, err
+	}
+	s.pool = pool
+	return s, nil
+}
+
+// Pipeline passes items through its stages. Every stage runs its function on its own worker pool and sends
+// the results to the next stage through a bounded channel. A stage only reads a new item once its pool has space
+// and its workers wait while the next stage is full, so a slow stage holds back all the stages before it
+// down to the source. The items are not kept in order within a stage.
+type Pipeline struct {
+	stages []*stage
+
+	mutex   sync.Mutex
+	started bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	errOnce sync.Once
+	err     error
+}
+
+// Start runs the pipeline on the items of the source and returns the output of the last stage, which must be
+// read until it is closed. Closing the source drains the pipeline stage by stage: every stage finishes its
+// items and closes its output before the next one does. The first failure of a stage cancels this stage
+// and the stages before it, while the stages after it drain the items already produced.
+// Cancelling the context stops all the stages.
+func (p *Pipeline) Start(ctx context.Context, source <-chan interface{}) (<-chan interface{}, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.started {
+		return nil, ErrStarted
+	}
+	p.started = true
+
+	runCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	// Every stage context derives from the one of the next stage, so cancelling a stage cancels the stages before it.
+	parent := runCtx
+	for i := len(p.stages) - 1; i >= 0; i-- {
+		s := p.stages[i]
+		s.ctx, s.cancel = context.WithCancel(parent)
+		parent = s.ctx
+	}
+
+	var stages sync.WaitGroup
+	in := source
+	for _, s := range p.stages {
+		out := make(chan interface{}, s.buffer)
+		stages.Add(1)
+		go func(s *stage, in <-chan interface{}, out chan<- interface{}) {
+			defer stages.Done()
+			s.run(in, out, p.fail)
+		}(s, in, out)
+		in = out
+	}
+
+	go func() {
+		stages.Wait()
+		if err := ctx.Err(); err != nil {
+			p.setErr(err)
+		}
+		cancel()
+		close(p.done)
+	}()
+	return in, nil
+}
+
+// Wait blocks until all the stages are finished and returns the first error, a failure of a stage
+// is returned as a *StageError.
+func (p *Pipeline) Wait() error {
+	p.mutex.Lock()
+	started := p.started
+	p.mutex.Unlock()
+	if !started {
+		return ErrNotStarted
+	}
+
+	<-p.done
+	return p.err
+}
+
+// Stop cancels all the stages and waits for them, the items in flight are dropped.
+func (p *Pipeline) Stop() {
+	p.mutex.Lock()
+	if !p.started {
+		p.started = true
+		p.mutex.Unlock()
+		p.setErr(ErrStopped)
+		p.stopPools()
+		close(p.done)
+		return
+	}
+	p.mutex.Unlock()
+
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	p.setErr(ErrStopped)
+	p.cancel()
+	<-p.done
+}
+
+// Stats returns a snapshot of every stage in processing order.
+func (p *Pipeline) Stats() []StageStats {
+	stats := make([]StageStats, 0, len(p.stages))
+	for _, s := range p.stages {
+		stats = append(stats, s.stats())
+	}
+	return stats
+}
+
+func (p *Pipeline) fail(s *stage, err error) {
+	p.setErr(&StageError{Stage: s.name, Err: err})
+	s.cancel()
+}
+
+func (p *Pipeline) setErr(err error) {
+	p.errOnce.Do(func() {
+		p.err = err
+	})
+}
+
+func (p *Pipeline) stopPools() {
+	for _, s := range p.stages {
+		s.pool.Stop()
+	}
+}
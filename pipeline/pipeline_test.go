@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/logger"
+	"github.com/vd09/gr_worker/worker_pool"
+)
+
+var errInvalid = errors.New("invalid item")
+
+func source(items ...interface{}) <-chan interface{} {
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+		for _, item := range items {
+			ch <- item
+		}
+	}()
+	return ch
+}
+
+func poolOptions(maxWorkers int32) []worker_pool.Option {
+	return []worker_pool.Option{
+		worker_pool.WithMaxWorkers(maxWorkers),
+		worker_pool.WithMaxTasks(maxWorkers),
+		worker_pool.WithLogger(logger.Discard),
+	}
+}
+
+func parse(ctx context.Context, item interface{}) (interface{}, error) {
+	value, err := strconv.Atoi(item.(string))
+	if err != nil {
+		return nil, errInvalid
+	}
+	return value, nil
+}
+
+func double(ctx context.Context, item interface{}) (interface{}, error) {
+	return item.(int) * 2, nil
+}
+
+func TestPipeline(t *testing.T) {
+	p, err := NewBuilder().
+		Stage("parse", parse, poolOptions(2)...).
+		AddStage(Stage{Name: "double", Func: double, Buffer: 4, Options: poolOptions(3)}).
+		Build()
+	if err != nil {
+		t.Fatalf("error building pipeline: %v", err)
+	}
+
+	out, err := p.Start(context.Background(), source("1", "2", "3", "4", "5"))
+	if err != nil {
+		t.Fatalf("error starting pipeline: %v", err)
+	}
+	var results []int
+	for result := range out {
+		results = append(results, result.(int))
+	}
+	if err := p.Wait(); err != nil {
+		t.Fatalf("unexpected pipeline error: %v", err)
+	}
+
+	sort.Ints(results)
+	expected := []int{2, 4, 6, 8, 10}
+	if len(results) != len(expected) {
+		t.Fatalf("unexpected results, got: %v, want: %v", results, expected)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Fatalf("unexpected results, got: %v, want: %v", results, expected)
+		}
+	}
+
+	for _, stats := range p.Stats() {
+		if stats.Received != 5 || stats.Emitted != 5 || stats.Failed != 0 || stats.Pool.CompletedTasks != 5 {
+			t.Errorf("unexpected stats of stage %s: %+v", stats.Name, stats)
+		}
+	}
+	if _, err := p.Start(context.Background(), source()); err != ErrStarted {
+		t.Errorf("unexpected error restarting the pipeline, got: %v, want: %v", err, ErrStarted)
+	}
+}
+
+func TestPipeline_StageError(t *testing.T) {
+	p, err := NewBuilder().
+		Stage("parse", parse, poolOptions(1)...).
+		Stage("double", double, poolOptions(1)...).
+		Build()
+	if err != nil {
+		t.Fatalf("error building pipeline: %v", err)
+	}
+
+	// The source never ends, the failure of the parse stage has to stop reading it.
+	items := make(chan interface{})
+	go func() {
+		items <- "1"
+		items <- "invalid"
+		for {
+			select {
+			case items <- "2":
+			case <-time.After(time.Second):
+				return
+			}
+		}
+	}()
+
+	out, _ := p.Start(context.Background(), items)
+	for range out {
+	}
+
+	err = p.Wait()
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != "parse" || !errors.Is(err, errInvalid) {
+		t.Fatalf("unexpected pipeline error: %v", err)
+	}
+	if stats := p.Stats()[0]; stats.Failed != 1 {
+		t.Errorf("unexpected failed items of the parse stage: %d", stats.Failed)
+	}
+}
+
+func TestPipeline_Backpressure(t *testing.T) {
+	p, err := NewBuilder().
+		Stage("parse", parse, poolOptions(1)...).
+		Stage("double", double, poolOptions(1)...).
+		Build()
+	if err != nil {
+		t.Fatalf("error building pipeline: %v", err)
+	}
+
+	items := make([]interface{}, 100)
+	for i := range items {
+		items[i] = strconv.Itoa(i)
+	}
+	out, _ := p.Start(context.Background(), source(items...))
+
+	// Nothing reads the output, every stage holds at most its queue, its workers and its buffer.
+	time.Sleep(20 * time.Millisecond)
+	if received := p.Stats()[0].Received; received > 10 {
+		t.Errorf("source is read without backpressure, received items: %d", received)
+	}
+
+	count := 0
+	for range out {
+		count++
+	}
+	if err := p.Wait(); err != nil || count != 100 {
+		t.Errorf("unexpected results: %d, error: %v", count, err)
+	}
+}
+
+func TestPipeline_Stop(t *testing.T) {
+	p, err := NewBuilder().Stage("parse", parse, poolOptions(1)...).Build()
+	if err != nil {
+		t.Fatalf("error building pipeline: %v", err)
+	}
+
+	out, _ := p.Start(context.Background(), make(chan interface{}))
+	p.Stop()
+	if _, ok := <-out; ok {
+		t.Error("output is not closed after stop")
+	}
+	if err := p.Wait(); err != ErrStopped {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrStopped)
+	}
+}
+
+func TestBuilder_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		builder       *Builder
+		expectedError error
+	}{
+		{"NoStages", NewBuilder(), ErrNoStages},
+		{"NilFunc", NewBuilder().Stage("parse", nil), ErrNilStageFunc},
+		{"NegativeBuffer", NewBuilder().AddStage(Stage{Func: parse, Buffer: -1}), ErrBuffer},
+		{"DuplicateName", NewBuilder().Stage("parse", parse).Stage("parse", parse), ErrDuplicateName},
+		{"InvalidPool", NewBuilder().Stage("parse", parse, worker_pool.WithMaxWorkers(0)), worker_pool.ErrMaxWorkers},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.builder.Build(); !errors.Is(err, test.expectedError) {
+				t.Errorf("unexpected error, got: %v, want: %v", err, test.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/worker_pool"
+)
+
+const DefaultBuffer = 1
+
+// StageFunc processes an item received from the previous stage, its result is sent to the next stage.
+type StageFunc func(ctx context.Context, item interface{}) (interface{}, error)
+
+// Stage describes a step of a pipeline.
+type Stage struct {
+	Name string
+	Func StageFunc
+	// Buffer is the capacity of the channel to the next stage, DefaultBuffer when zero.
+	Buffer int
+	// Options configure the worker pool of the stage, e.g. its min and max workers or its worker strategy.
+	Options []worker_pool.Option
+}
+
+// StageStats is a snapshot of a stage. Received counts the items read from the previous stage, Emitted the items
+// sent to the next one and Failed the items for which the stage function returned an error or panicked.
+type StageStats struct {
+	Name     string
+	Received uint64
+	Emitted  uint64
+	Failed   uint64
+	Pool     worker_pool.Stats
+}
+
+// StageError is returned by Pipeline.Wait for the first item which failed in a stage.
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("pipeline stage %s failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+type stage struct {
+	name   string
+	fn     StageFunc
+	buffer int
+	pool   *worker_pool.WorkerPoolAdapter
+
+	// ctx is cancelled on the first failure of the stage or of a downstream stage.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	received atomic.Uint64
+	emitted  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+// run submits the items of the previous stage to the pool of the stage until its input is closed or the stage
+// is cancelled. The output is closed once the submitted items are finished, so the next stage drains after it.
+func (s *stage) run(in <-chan interface{}, out chan<- interface{}, fail func(s *stage, err error)) {
+	defer close(out)
+	defer s.pool.WaitAndStop()
+
+	for s.ctx.Err() == nil {
+		select {
+		case item, ok := <-in:
+			if !ok {
+				return
+			}
+			s.received.Add(1)
+			_, err := s.pool.SubmitWithContext(s.ctx, func(ctx context.Context) error {
+				return s.process(ctx, item, out, fail)
+			})
+			if err != nil {
+				fail(s, err)
+				return
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// process executes the stage function and sends its result to the next stage, waiting while the next stage
+// is full, so a slow stage holds back the stages before it.
+func (s *stage) process(ctx context.Context, item interface{}, out chan<- interface{}, fail func(s *stage, err error)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &gr_worker.PanicError{Value: r, Stack: debug.Stack()}
+		}
+		// Errors of the items cancelled with the stage are not failures of the stage.
+		if err != nil && s.ctx.Err() == nil {
+			s.failed.Add(1)
+			fail(s, err)
+		}
+	}()
+
+	result, err := s.fn(ctx, item)
+	if err != nil {
+		return err
+	}
+	select {
+	case out <- result:
+		s.emitted.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *stage) stats() StageStats {
+	return StageStats{
+		Name:     s.name,
+		Received: s.received.Load(),
+		Emitted:  s.emitted.Load(),
+		Failed:   s.failed.Load(),
+		Pool:     s.pool.Stats(),
+	}
+}
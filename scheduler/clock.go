@@ -0,0 +1,40 @@
+package scheduler
+
+import "time"
+
+// Clock is the source of time of a Scheduler, it can be replaced in tests to control the job executions.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the timer created by a Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// RealClock is the Clock backed by the time package.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (rt realTimer) C() <-chan time.Time {
+	return rt.timer.C
+}
+
+func (rt realTimer) Stop() bool {
+	return rt.timer.Stop()
+}
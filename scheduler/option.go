@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/vd09/gr_worker/logger"
+)
+
+type Option func(*Scheduler)
+
+// Clock allows to replace the source of time of the scheduler, mainly for tests
+func WithClock(clock Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+// Logger allows to change the logger used to report jobs which can't be submitted
+func WithLogger(logger logger.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// OverlapPolicy allows to change the overlap policy of the jobs added without an explicit Job
+func WithOverlapPolicy(policy OverlapPolicy) Option {
+	return func(s *Scheduler) {
+		s.overlapPolicy = policy
+	}
+}
+
+// Jitter allows to change the jitter of the jobs added without an explicit Job
+func WithJitter(jitter time.Duration) Option {
+	return func(s *Scheduler) {
+		s.jitter = jitter
+	}
+}
@@ -0,0 +1,256 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCronFieldCount = errors.New("cron spec must have 5 or 6 fields")
+	ErrInterval       = errors.New("interval can't be less than or equal to zero")
+)
+
+// Schedule returns the next activation time strictly after the given time.
+// A zero time means the schedule has no further activation.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every returns a Schedule activated at a fixed interval.
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule{interval: interval}
+}
+
+func (is intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(is.interval)
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = cronField{name: "second", min: 0, max: 59}
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// cronSchedule keeps the allowed values of every cron field as a bit set.
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool
+}
+
+// ParseCron parses a standard cron spec with 5 fields (minute hour day-of-month month day-of-week)
+// or 6 fields with a leading second field. Fields accept *, ?, lists, ranges, steps and the names of
+// months and week days. The macros @yearly, @monthly, @weekly, @daily, @hourly and @every <duration>
+// are supported as well.
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, err
+		}
+		if interval <= 0 {
+			return nil, ErrInterval
+		}
+		return Every(interval), nil
+	}
+	if macro, ok := cronMacros[spec]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, ErrCronFieldCount
+	}
+
+	cs := &cronSchedule{}
+	var err error
+	if cs.second, err = secondField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if cs.minute, err = minuteField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if cs.hour, err = hourField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if cs.dom, err = domField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if cs.month, err = monthField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if cs.dow, err = dowField.parse(fields[5]); err != nil {
+		return nil, err
+	}
+	// Sunday can be written as 0 or 7
+	if cs.dow&(1<<7) != 0 {
+		cs.dow |= 1
+	}
+	cs.domStar = isStar(fields[3])
+	cs.dowStar = isStar(fields[5])
+	return cs, nil
+}
+
+func isStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// parse returns the bit set of the values allowed by a comma separated cron field.
+func (cf cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		partBits, err := cf.parsePart(part)
+		if err != nil {
+			return 0, err
+		}
+		bits |= partBits
+	}
+	return bits, nil
+}
+
+func (cf cronField) parsePart(part string) (uint64, error) {
+	rangePart, step := part, 1
+	if index := strings.Index(part, "/"); index >= 0 {
+		var err error
+		rangePart = part[:index]
+		if step, err = strconv.Atoi(part[index+1:]); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q in %s field", part[index+1:], cf.name)
+		}
+	}
+
+	start, end := cf.min, cf.max
+	switch {
+	case isStar(rangePart):
+	case strings.Contains(rangePart, "-"):
+		bounds := strings.SplitN(rangePart, "-", 2)
+		var err error
+		if start, err = cf.parseValue(bounds[0]); err != nil {
+			return 0, err
+		}
+		if end, err = cf.parseValue(bounds[1]); err != nil {
+			return 0, err
+		}
+	default:
+		value, err := cf.parseValue(rangePart)
+		if err != nil {
+			return 0, err
+		}
+		start = value
+		if step == 1 {
+			end = value
+		}
+	}
+	if start > end {
+		return 0, fmt.Errorf("invalid range %q in %s field", rangePart, cf.name)
+	}
+
+	var bits uint64
+	for value := start; value <= end; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits, nil
+}
+
+func (cf cronField) parseValue(value string) (int, error) {
+	if number, ok := cf.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", value, cf.name)
+	}
+	if number < cf.min || number > cf.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d] in %s field", number, cf.min, cf.max, cf.name)
+	}
+	return number, nil
+}
+
+// Next finds the next matching time by moving forward field by field, from month to second.
+// It gives up after five years, which only happens for impossible dates like the 30th of February.
+func (cs *cronSchedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for cs.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !cs.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for cs.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for cs.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Truncate(time.Minute).Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	for cs.second&(1<<uint(t.Second())) == 0 {
+		t = t.Truncate(time.Second).Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
+
+// dayMatches applies the cron rule for days: when both day fields are restricted, matching either one is enough.
+func (cs *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cs.dom&(1<<uint(t.Day())) != 0
+	dowMatch := cs.dow&(1<<uint(t.Weekday())) != 0
+	if cs.domStar || cs.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	from := time.Date(2024, time.May, 10, 10, 30, 15, 0, time.UTC) // Friday
+	tests := []struct {
+		name     string
+		spec     string
+		expected time.Time
+	}{
+		{"EveryMinute", "* * * * *", time.Date(2024, time.May, 10, 10, 31, 0, 0, time.UTC)},
+		{"EverySecond", "* * * * * *", time.Date(2024, time.May, 10, 10, 30, 16, 0, time.UTC)},
+		{"EveryFiveMinutes", "*/5 * * * *", time.Date(2024, time.May, 10, 10, 35, 0, 0, time.UTC)},
+		{"Range", "0 9-17 * * *", time.Date(2024, time.May, 10, 11, 0, 0, 0, time.UTC)},
+		{"List", "15,45 * * * *", time.Date(2024, time.May, 10, 10, 45, 0, 0, time.UTC)},
+		{"NextDay", "0 8 * * *", time.Date(2024, time.May, 11, 8, 0, 0, 0, time.UTC)},
+		{"WeekDayName", "0 0 * * MON", time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)},
+		{"SundayAsSeven", "0 0 * * 7", time.Date(2024, time.May, 12, 0, 0, 0, 0, time.UTC)},
+		{"MonthName", "0 0 1 jan *", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"DayOfMonthOrWeek", "0 0 20 * 6", time.Date(2024, time.May, 11, 0, 0, 0, 0, time.UTC)},
+		{"LeapDay", "0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"SixFields", "30 */10 * * * *", time.Date(2024, time.May, 10, 10, 30, 30, 0, time.UTC)},
+		{"Hourly", "@hourly", time.Date(2024, time.May, 10, 11, 0, 0, 0, time.UTC)},
+		{"Every", "@every 90s", time.Date(2024, time.May, 10, 10, 31, 45, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := ParseCron(test.spec)
+			if err != nil {
+				t.Fatalf("error parsing %q: %v", test.spec, err)
+			}
+			if next := schedule.Next(from); !next.Equal(test.expected) {
+				t.Errorf("unexpected next time, got: %v, want: %v", next, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every -1s"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestParseCron_Impossible(t *testing.T) {
+	schedule, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("error parsing spec: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("impossible schedule returned a time: %v", next)
+	}
+}
@@ -0,0 +1,274 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/logger"
+	"github.com/vd09/gr_worker/worker_pool"
+)
+
+var (
+	ErrNilPool          = errors.New("worker pool can't be nil")
+	ErrNilSchedule      = errors.New("schedule can't be nil")
+	ErrJitter           = errors.New("jitter can't be less than zero")
+	ErrSchedulerStopped = errors.New("scheduler is stopped")
+)
+
+// OverlapPolicy decides what happens when a job is due while its previous run is still queued or running.
+type OverlapPolicy int
+
+const (
+	// SKIP_IF_RUNNING drops the activation.
+	SKIP_IF_RUNNING OverlapPolicy = iota
+	// QUEUE_IF_RUNNING runs the job once more right after the current run; activations are coalesced,
+	// so at most one run is waiting.
+	QUEUE_IF_RUNNING
+	// ALLOW_CONCURRENT submits every activation, runs can execute concurrently on the pool workers.
+	ALLOW_CONCURRENT
+)
+
+type JobID int64
+
+// Job describes a periodic task submitted to the worker pool of a Scheduler.
+type Job struct {
+	Schedule      Schedule
+	OverlapPolicy OverlapPolicy
+	// Jitter delays every activation by a random duration in [0, Jitter).
+	Jitter   time.Duration
+	TaskFunc interface{}
+	Params   []interface{}
+}
+
+// Scheduler submits periodic jobs into a WorkerPool.
+type Scheduler struct {
+	pool   worker_pool.WorkerPool
+	clock  Clock
+	logger logger.Logger
+
+	overlapPolicy OverlapPolicy
+	jitter        time.Duration
+
+	mutex   sync.Mutex
+	nextID  JobID
+	jobs    map[JobID]*scheduledJob
+	stopped bool
+	running sync.WaitGroup
+}
+
+type scheduledJob struct {
+	id   JobID
+	job  Job
+	stop chan struct{}
+
+	// runs counts the submitted runs which are not finished yet, pending marks a run queued by QUEUE_IF_RUNNING.
+	mutex   sync.Mutex
+	runs    int
+	pending bool
+}
+
+func NewScheduler(pool worker_pool.WorkerPool, options ...Option) (*Scheduler, error) {
+	if pool == nil {
+		return nil, ErrNilPool
+	}
+
+	s := &Scheduler{
+		pool:   pool,
+		clock:  RealClock,
+		logger: logger.Std,
+		jobs:   make(map[JobID]*scheduledJob),
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	if s.jitter < 0 {
+		return nil, ErrJitter
+	}
+	if s.logger == nil {
+		s.logger = logger.Discard
+	}
+	return s, nil
+}
+
+// AddCron schedules the task with a cron spec, see ParseCron for the supported syntax.
+func (s *Scheduler) AddCron(spec string, taskFunc interface{}, params ...interface{}) (JobID, error) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return 0, err
+	}
+	return s.AddSchedule(schedule, taskFunc, params...)
+}
+
+// AddInterval schedules the task at a fixed interval, the first run happens one interval after it is added.
+func (s *Scheduler) AddInterval(interval time.Duration, taskFunc interface{}, params ...interface{}) (JobID, error) {
+	if interval <= 0 {
+		return 0, ErrInterval
+	}
+	return s.AddSchedule(Every(interval), taskFunc, params...)
+}
+
+// AddSchedule schedules the task with the overlap policy and jitter of the scheduler.
+func (s *Scheduler) AddSchedule(schedule Schedule, taskFunc interface{}, params ...interface{}) (JobID, error) {
+	return s.AddJob(Job{
+		Schedule:      schedule,
+		OverlapPolicy: s.overlapPolicy,
+		Jitter:        s.jitter,
+		TaskFunc:      taskFunc,
+		Params:        params,
+	})
+}
+
+// AddJob starts scheduling the job and returns its id.
+func (s *Scheduler) AddJob(job Job) (JobID, error) {
+	if job.Schedule == nil {
+		return 0, ErrNilSchedule
+	}
+	if interval, ok := job.Schedule.(intervalSchedule); ok && interval.interval <= 0 {
+		return 0, ErrInterval
+	}
+	if job.Jitter < 0 {
+		return 0, ErrJitter
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return 0, ErrSchedulerStopped
+	}
+
+	s.nextID++
+	sj := &scheduledJob{
+		id:   s.nextID,
+		job:  job,
+		stop: make(chan struct{}),
+	}
+	s.jobs[sj.id] = sj
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.runJob(sj)
+	}()
+	return sj.id, nil
+}
+
+// Remove stops scheduling the job, runs already submitted to the pool are not affected.
+func (s *Scheduler) Remove(id JobID) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sj, ok := s.jobs[id]
+	if !ok {
+		return false
+	}
+	delete(s.jobs, id)
+	close(sj.stop)
+	return true
+}
+
+// Stop removes all the jobs and waits until none of them is submitting to the pool. The pool is not stopped.
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	s.stopped = true
+	for id, sj := range s.jobs {
+		delete(s.jobs, id)
+		close(sj.stop)
+	}
+	s.mutex.Unlock()
+
+	s.running.Wait()
+}
+
+func (s *Scheduler) runJob(sj *scheduledJob) {
+	next := sj.job.Schedule.Next(s.clock.Now())
+	for !next.IsZero() {
+		delay := next.Sub(s.clock.Now())
+		if sj.job.Jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(sj.job.Jitter)))
+		}
+
+		timer := s.clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-sj.stop:
+			timer.Stop()
+			return
+		}
+
+		s.activate(sj)
+
+		// Activations missed while the pool was blocking the submission are skipped.
+		now := s.clock.Now()
+		if next = sj.job.Schedule.Next(next); !next.IsZero() && next.Before(now) {
+			next = sj.job.Schedule.Next(now)
+		}
+	}
+}
+
+// activate submits a run of the job according to its overlap policy.
+func (s *Scheduler) activate(sj *scheduledJob) {
+	sj.mutex.Lock()
+	if sj.runs > 0 {
+		switch sj.job.OverlapPolicy {
+		case SKIP_IF_RUNNING:
+			sj.mutex.Unlock()
+			return
+		case QUEUE_IF_RUNNING:
+			sj.pending = true
+			sj.mutex.Unlock()
+			return
+		}
+	}
+	sj.runs++
+	sj.mutex.Unlock()
+
+	s.submit(sj)
+}
+
+// submit hands a run over to the pool. The run slot is released once its future finishes, so a run
+// dropped by the pool frees it as well and a run retried by the pool keeps it until its last attempt.
+func (s *Scheduler) submit(sj *scheduledJob) {
+	task := gr_worker.NewTask(sj.job.TaskFunc, sj.job.Params...)
+	future, err := s.pool.Submit(func(ctx context.Context) error {
+		return task.ExecuteTaskWithContext(ctx)
+	})
+	if err != nil {
+		s.logger.Printf("[ERROR] Job %d is not submitted to the worker pool: %v", sj.id, err)
+		s.finish(sj)
+		return
+	}
+	go func() {
+		<-future.Done()
+		s.finish(sj)
+	}()
+}
+
+// finish ends a run and submits the run queued by QUEUE_IF_RUNNING.
+func (s *Scheduler) finish(sj *scheduledJob) {
+	sj.mutex.Lock()
+	if !sj.pending || s.isRemoved(sj) {
+		sj.runs--
+		sj.pending = false
+		sj.mutex.Unlock()
+		return
+	}
+	sj.pending = false
+	sj.mutex.Unlock()
+
+	// The finished run hands its slot over to the queued run.
+	s.submit(sj)
+}
+
+func (s *Scheduler) isRemoved(sj *scheduledJob) bool {
+	select {
+	case <-sj.stop:
+		return true
+	default:
+		return false
+	}
+}
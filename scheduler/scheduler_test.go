@@ -0,0 +1,379 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/logger"
+	"github.com/vd09/gr_worker/worker_pool"
+)
+
+type fakeTimer struct {
+	clock    *fakeClock
+	deadline time.Time
+	ch       chan time.Time
+	stopped  bool
+}
+
+func (ft *fakeTimer) C() <-chan time.Time {
+	return ft.ch
+}
+
+func (ft *fakeTimer) Stop() bool {
+	ft.clock.mutex.Lock()
+	defer ft.clock.mutex.Unlock()
+	ft.stopped = true
+	return true
+}
+
+type fakeClock struct {
+	mutex     sync.Mutex
+	now       time.Time
+	timers    []*fakeTimer
+	durations []time.Duration
+	created   chan struct{}
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Date(2024, time.May, 10, 10, 0, 0, 0, time.UTC),
+		created: make(chan struct{}, 100),
+	}
+}
+
+func (fc *fakeClock) Now() time.Time {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+	return fc.now
+}
+
+func (fc *fakeClock) NewTimer(d time.Duration) Timer {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	timer := &fakeTimer{clock: fc, deadline: fc.now.Add(d), ch: make(chan time.Time, 1)}
+	fc.timers = append(fc.timers, timer)
+	fc.durations = append(fc.durations, d)
+	fc.created <- struct{}{}
+	return timer
+}
+
+func (fc *fakeClock) Advance(d time.Duration) {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	fc.now = fc.now.Add(d)
+	pending := fc.timers[:0]
+	for _, timer := range fc.timers {
+		if timer.stopped {
+			continue
+		}
+		if timer.deadline.After(fc.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- fc.now
+	}
+	fc.timers = pending
+}
+
+func waitForTimer(t *testing.T, clock *fakeClock) {
+	t.Helper()
+	select {
+	case <-clock.created:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not create a timer")
+	}
+}
+
+func waitForSignal(t *testing.T, signal <-chan struct{}, message string) {
+	t.Helper()
+	select {
+	case <-signal:
+	case <-time.After(time.Second):
+		t.Fatal(message)
+	}
+}
+
+func newTestScheduler(t *testing.T, clock *fakeClock) (*Scheduler, worker_pool.WorkerPool) {
+	pool, err := worker_pool.NewWorkerPool(worker_pool.WithMaxWorkers(2), worker_pool.WithMaxTasks(4))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	s, err := NewScheduler(pool, WithClock(clock), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating scheduler: %v", err)
+	}
+	return s, pool
+}
+
+func TestScheduler_AddInterval(t *testing.T) {
+	clock := newFakeClock()
+	s, pool := newTestScheduler(t, clock)
+	defer pool.Stop()
+	defer s.Stop()
+
+	runs := make(chan struct{}, 2)
+	id, err := s.AddInterval(time.Minute, func() { runs <- struct{}{} })
+	if err != nil {
+		t.Fatalf("error adding job: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		waitForTimer(t, clock)
+		clock.Advance(time.Minute)
+		waitForSignal(t, runs, "job did not run after its interval")
+		waitForReleasedRuns(t, scheduledJobOf(s, id))
+	}
+}
+
+func TestScheduler_AddCron_Remove(t *testing.T) {
+	clock := newFakeClock()
+	s, pool := newTestScheduler(t, clock)
+	defer pool.Stop()
+	defer s.Stop()
+
+	var runs atomic.Int32
+	id, err := s.AddCron("*/5 * * * *", func() { runs.Add(1) })
+	if err != nil {
+		t.Fatalf("error adding job: %v", err)
+	}
+
+	waitForTimer(t, clock)
+	if !s.Remove(id) {
+		t.Error("job is not removed")
+	}
+	clock.Advance(5 * time.Minute)
+	time.Sleep(10 * time.Millisecond)
+	if runs.Load() != 0 {
+		t.Error("removed job is executed")
+	}
+	if s.Remove(id) {
+		t.Error("job is removed twice")
+	}
+}
+
+func TestScheduler_OverlapPolicies(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       OverlapPolicy
+		expectedRuns int32
+	}{
+		{"Skip", SKIP_IF_RUNNING, 1},
+		{"Queue", QUEUE_IF_RUNNING, 2},
+		{"AllowConcurrent", ALLOW_CONCURRENT, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := newFakeClock()
+			s, pool := newTestScheduler(t, clock)
+
+			var runs atomic.Int32
+			started := make(chan struct{}, 2)
+			release := make(chan struct{})
+			_, err := s.AddJob(Job{
+				Schedule:      Every(time.Minute),
+				OverlapPolicy: test.policy,
+				TaskFunc: func() {
+					runs.Add(1)
+					started <- struct{}{}
+					<-release
+				},
+			})
+			if err != nil {
+				t.Fatalf("error adding job: %v", err)
+			}
+
+			waitForTimer(t, clock)
+			clock.Advance(time.Minute)
+			waitForSignal(t, started, "first run did not start")
+
+			waitForTimer(t, clock)
+			clock.Advance(time.Minute)
+			waitForTimer(t, clock)
+			if test.policy == ALLOW_CONCURRENT {
+				waitForSignal(t, started, "concurrent run did not start")
+			}
+
+			close(release)
+			if test.policy == QUEUE_IF_RUNNING {
+				waitForSignal(t, started, "queued run did not start")
+			}
+			s.Stop()
+			pool.WaitAndStop()
+
+			if runs.Load() != test.expectedRuns {
+				t.Errorf("unexpected runs, got: %d, want: %d", runs.Load(), test.expectedRuns)
+			}
+		})
+	}
+}
+
+func scheduledJobOf(s *Scheduler, id JobID) *scheduledJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.jobs[id]
+}
+
+// waitForReleasedRuns waits until the runs of the job release their slots and returns the remaining runs.
+func waitForReleasedRuns(t *testing.T, sj *scheduledJob) int {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		sj.mutex.Lock()
+		runs := sj.runs
+		sj.mutex.Unlock()
+		if runs <= 0 {
+			return runs
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run slot of job %d is not released, runs: %d", sj.id, runs)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduler_DroppedRun(t *testing.T) {
+	clock := newFakeClock()
+	pool, err := worker_pool.NewWorkerPool(worker_pool.WithMaxWorkers(1), worker_pool.WithMaxTasks(1),
+		worker_pool.WithRejectionPolicy(worker_pool.DROP_NEWEST), worker_pool.WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer pool.Stop()
+	s, err := NewScheduler(pool, WithClock(clock), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating scheduler: %v", err)
+	}
+	defer s.Stop()
+
+	// The worker is busy and the queue is full, the first run of the job is dropped by the pool.
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.AddTask(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	filler, _ := pool.Submit(func() {})
+
+	runs := make(chan struct{}, 1)
+	id, err := s.AddJob(Job{Schedule: Every(time.Minute), OverlapPolicy: SKIP_IF_RUNNING, TaskFunc: func() { runs <- struct{}{} }})
+	if err != nil {
+		t.Fatalf("error adding job: %v", err)
+	}
+	waitForTimer(t, clock)
+	clock.Advance(time.Minute)
+	waitForTimer(t, clock)
+	if runs := waitForReleasedRuns(t, scheduledJobOf(s, id)); runs != 0 {
+		t.Errorf("unexpected runs after the dropped run, got: %d, want: 0", runs)
+	}
+
+	close(release)
+	if err := filler.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected filler task error: %v", err)
+	}
+	clock.Advance(time.Minute)
+	waitForSignal(t, runs, "job did not run after its dropped run")
+}
+
+func TestScheduler_RetriedRun(t *testing.T) {
+	const backoff = 50 * time.Millisecond
+	clock := newFakeClock()
+	pool, err := worker_pool.NewWorkerPool(worker_pool.WithMaxWorkers(2), worker_pool.WithMaxTasks(4),
+		worker_pool.WithRetryPolicy(worker_pool.RetryPolicy{MaxAttempts: 2, InitialBackoff: backoff}),
+		worker_pool.WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	s, err := NewScheduler(pool, WithClock(clock), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating scheduler: %v", err)
+	}
+
+	attempts := make(chan time.Time, 4)
+	id, err := s.AddJob(Job{
+		Schedule:      Every(time.Minute),
+		OverlapPolicy: SKIP_IF_RUNNING,
+		TaskFunc: func() error {
+			attempts <- time.Now()
+			return errors.New("temporary failure")
+		},
+	})
+	if err != nil {
+		t.Fatalf("error adding job: %v", err)
+	}
+	sj := scheduledJobOf(s, id)
+
+	waitForTimer(t, clock)
+	clock.Advance(time.Minute)
+	first := <-attempts
+
+	// The run keeps its slot while the pool waits to retry it, so this activation is skipped.
+	waitForTimer(t, clock)
+	clock.Advance(time.Minute)
+	waitForTimer(t, clock)
+	select {
+	case second := <-attempts:
+		if second.Sub(first) < backoff {
+			t.Errorf("job ran again before the retry of its previous run, after: %v", second.Sub(first))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("retry did not run")
+	}
+
+	// The slot is released once for the run, not once per attempt.
+	if runs := waitForReleasedRuns(t, sj); runs != 0 {
+		t.Errorf("unexpected runs after the retried run, got: %d, want: 0", runs)
+	}
+	s.Stop()
+	pool.WaitAndStop()
+	if len(attempts) != 0 {
+		t.Errorf("unexpected attempts after the retried run: %d", len(attempts))
+	}
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	clock := newFakeClock()
+	s, pool := newTestScheduler(t, clock)
+	defer pool.Stop()
+	defer s.Stop()
+
+	_, err := s.AddJob(Job{Schedule: Every(time.Minute), Jitter: 10 * time.Second, TaskFunc: func() {}})
+	if err != nil {
+		t.Fatalf("error adding job: %v", err)
+	}
+	waitForTimer(t, clock)
+
+	clock.mutex.Lock()
+	delay := clock.durations[0]
+	clock.mutex.Unlock()
+	if delay < time.Minute || delay >= time.Minute+10*time.Second {
+		t.Errorf("delay %v is out of the jitter range", delay)
+	}
+}
+
+func TestScheduler_Validation(t *testing.T) {
+	if _, err := NewScheduler(nil); err != ErrNilPool {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrNilPool)
+	}
+
+	s, pool := newTestScheduler(t, newFakeClock())
+	defer pool.Stop()
+	if _, err := s.AddInterval(0, func() {}); err != ErrInterval {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrInterval)
+	}
+	if _, err := s.AddJob(Job{TaskFunc: func() {}}); err != ErrNilSchedule {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrNilSchedule)
+	}
+
+	s.Stop()
+	if _, err := s.AddInterval(time.Second, func() {}); err != ErrSchedulerStopped {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrSchedulerStopped)
+	}
+}
@@ -1,14 +1,23 @@
 package gr_worker
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime/debug"
+)
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
 )
 
 type Task struct {
-	fn     interface{}
-	params []interface{}
+	ctx     context.Context
+	fn      interface{}
+	params  []interface{}
+	results []interface{}
 }
 
 func NewTask(taskFunc interface{}, params ...interface{}) *Task {
@@ -18,20 +27,91 @@ func NewTask(taskFunc interface{}, params ...interface{}) *Task {
 	}
 }
 
+// NewTaskWithContext creates a task whose context is injected as the first parameter
+// when the first parameter of the task function is a context.Context.
+func NewTaskWithContext(ctx context.Context, taskFunc interface{}, params ...interface{}) *Task {
+	return &Task{
+		ctx:    ctx,
+		fn:     taskFunc,
+		params: params,
+	}
+}
+
+// Func returns the task function.
+func (t *Task) Func() interface{} {
+	return t.fn
+}
+
+// Params returns the parameters given while creating the task.
+func (t *Task) Params() []interface{} {
+	return t.params
+}
+
+// Context returns the context given while creating the task, it is nil for tasks created by NewTask.
+func (t *Task) Context() context.Context {
+	return t.ctx
+}
+
+// Results returns the values returned by the last execution of the task function.
+// A trailing error return value is not part of the results, it is returned by ExecuteTask instead.
+func (t *Task) Results() []interface{} {
+	return t.results
+}
+
 // ExecuteTask executes a given task function with parameters.
+// If the last return value of the task function is an error, it is returned as the error of the execution.
+// A panic inside the task function is recovered and returned as a *PanicError.
 func (t *Task) ExecuteTask() error {
+	return t.ExecuteTaskWithContext(t.ctx)
+}
+
+// ExecuteTaskWithContext executes the task like ExecuteTask, the given context is passed as the first parameter
+// when the first parameter of the task function is a context.Context. A context present in the parameters
+// is passed instead, but it is cancelled as well once the given context is done.
+func (t *Task) ExecuteTaskWithContext(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	// Closures without parameters are called directly to avoid reflection on the hot path.
+	if len(t.params) == 0 {
+		switch fn := t.fn.(type) {
+		case func() error:
+			t.results = nil
+			return fn()
+		case func():
+			t.results = nil
+			fn()
+			return nil
+		case func(context.Context) error:
+			if ctx != nil {
+				t.results = nil
+				return fn(ctx)
+			}
+		}
+	}
+
 	task := reflect.ValueOf(t.fn)
 	if task.Kind() != reflect.Func {
 		return errors.New(fmt.Sprintf("Error: taskFunc %v is not a function", task))
 	}
 
-	if len(t.params) < task.Type().NumIn() {
+	params := t.params
+	if ctx != nil {
+		var release func()
+		params, release = t.contextParams(ctx, task.Type())
+		defer release()
+	}
+
+	if len(params) < task.Type().NumIn() {
 		return errors.New(fmt.Sprintf("Error: number of parameters does not match; expected:%v actual:%v",
-			task.Type().NumIn(), len(t.params)))
+			task.Type().NumIn(), len(params)))
 	}
 
-	inputs := make([]reflect.Value, len(t.params))
-	for i, param := range t.params {
+	inputs := make([]reflect.Value, len(params))
+	for i, param := range params {
 		if param == nil {
 			inputs[i] = reflect.Zero(task.Type().In(i)) // Pass zero value for nil parameter
 		} else {
@@ -40,11 +120,60 @@ func (t *Task) ExecuteTask() error {
 	}
 
 	// Call the task function and handle the return values
-	task.Call(inputs)
-	//results := task.Call(inputs)
-	//if len(results) > 0 && !results[0].IsNil() {
-	//	// If the first return value is not nil, assume it's an error
-	//	return results[0].Interface().(error)
-	//}
-	return nil
+	outputs := task.Call(inputs)
+
+	if last := len(outputs) - 1; last >= 0 && task.Type().Out(last) == errorType {
+		if !outputs[last].IsNil() {
+			err = outputs[last].Interface().(error)
+		}
+		outputs = outputs[:last]
+	}
+
+	results := make([]interface{}, len(outputs))
+	for i, output := range outputs {
+		results[i] = output.Interface()
+	}
+	t.results = results
+	return err
+}
+
+// contextParams passes the context as the first parameter when the first parameter of the task function
+// is a context.Context. A nil first parameter is the slot of the context and is replaced by it. A context
+// already provided by the task parameters is kept, but it is also cancelled once the given context is done.
+// The returned function releases the resources of the combined context.
+func (t *Task) contextParams(ctx context.Context, taskType reflect.Type) ([]interface{}, func()) {
+	if taskType.NumIn() == 0 || taskType.In(0) != contextType {
+		return t.params, func() {}
+	}
+	if len(t.params) == 0 {
+		return []interface{}{ctx}, func() {}
+	}
+	switch explicitCtx := t.params[0].(type) {
+	case context.Context:
+		combinedCtx, cancel := combineContexts(explicitCtx, ctx)
+		return append([]interface{}{combinedCtx}, t.params[1:]...), cancel
+	case nil:
+		return append([]interface{}{ctx}, t.params[1:]...), func() {}
+	}
+	return append([]interface{}{ctx}, t.params...), func() {}
+}
+
+// combineContexts derives a context from the explicit one which is also done once ctx is done.
+// The deadline of ctx is applied as a deadline, so a timeout is still reported as context.DeadlineExceeded.
+func combineContexts(explicitCtx, ctx context.Context) (context.Context, context.CancelFunc) {
+	combinedCtx, cancelCtx := context.WithCancel(explicitCtx)
+	cancelDeadline := context.CancelFunc(func() {})
+	if deadline, ok := ctx.Deadline(); ok {
+		combinedCtx, cancelDeadline = context.WithDeadline(combinedCtx, deadline)
+	}
+	stopAfterFunc := context.AfterFunc(ctx, func() {
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			cancelCtx()
+		}
+	})
+	return combinedCtx, func() {
+		stopAfterFunc()
+		cancelDeadline()
+		cancelCtx()
+	}
 }
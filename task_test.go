@@ -0,0 +1,139 @@
+package gr_worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTask_ExecuteTask_ReturnsTrailingError(t *testing.T) {
+	expectedErr := errors.New("task failed")
+	task := NewTask(func(value int) (int, error) {
+		return value * 2, expectedErr
+	}, 4)
+
+	if err := task.ExecuteTask(); err != expectedErr {
+		t.Errorf("unexpected error, got: %v, want: %v", err, expectedErr)
+	}
+	results := task.Results()
+	if len(results) != 1 || results[0] != 8 {
+		t.Errorf("unexpected results, got: %#v, want: [8]", results)
+	}
+}
+
+func TestTask_ExecuteTask_NilError(t *testing.T) {
+	task := NewTask(func(a, b string) (string, int, error) {
+		return a + b, len(a + b), nil
+	}, "gr", "worker")
+
+	if err := task.ExecuteTask(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	results := task.Results()
+	if len(results) != 2 || results[0] != "grworker" || results[1] != 8 {
+		t.Errorf("unexpected results, got: %#v", results)
+	}
+}
+
+func TestTask_ExecuteTask_NonTrailingError(t *testing.T) {
+	expectedErr := errors.New("not trailing")
+	task := NewTask(func() (error, int) {
+		return expectedErr, 1
+	})
+
+	if err := task.ExecuteTask(); err != nil {
+		t.Errorf("only trailing error should be returned, got: %v", err)
+	}
+	results := task.Results()
+	if len(results) != 2 || results[0] != expectedErr || results[1] != 1 {
+		t.Errorf("unexpected results, got: %#v", results)
+	}
+}
+
+func TestTask_ExecuteTask_NotAFunction(t *testing.T) {
+	task := NewTask(7)
+	if err := task.ExecuteTask(); err == nil {
+		t.Error("expected error for non function task")
+	}
+}
+
+func TestTask_ExecuteTask_RecoversPanic(t *testing.T) {
+	task := NewTask(func() { panic("boom") })
+
+	err := task.ExecuteTask()
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected *PanicError, got: %#v", err)
+	}
+	if panicErr.Value != "boom" {
+		t.Errorf("unexpected panic value, got: %v, want: boom", panicErr.Value)
+	}
+	if len(panicErr.Stack) == 0 {
+		t.Error("stack trace is not captured")
+	}
+}
+
+type contextKey struct{}
+
+func TestTask_ExecuteTaskWithContext_InjectsContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+	task := NewTaskWithContext(ctx, func(ctx context.Context, suffix string) string {
+		return ctx.Value(contextKey{}).(string) + suffix
+	}, "-suffix")
+
+	if err := task.ExecuteTask(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := task.Results()
+	if len(results) != 1 || results[0] != "value-suffix" {
+		t.Errorf("unexpected results, got: %#v, want: [value-suffix]", results)
+	}
+}
+
+func TestTask_ExecuteTaskWithContext_KeepsExplicitContext(t *testing.T) {
+	explicitCtx := context.WithValue(context.Background(), contextKey{}, "explicit")
+	task := NewTask(func(ctx context.Context) string {
+		return ctx.Value(contextKey{}).(string)
+	}, explicitCtx)
+
+	if err := task.ExecuteTaskWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := task.Results()
+	if len(results) != 1 || results[0] != "explicit" {
+		t.Errorf("unexpected results, got: %#v, want: [explicit]", results)
+	}
+}
+
+func TestTask_ExecuteTaskWithContext_NilContextParam(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+	task := NewTask(func(ctx context.Context, suffix string) string {
+		return ctx.Value(contextKey{}).(string) + suffix
+	}, nil, "-suffix")
+
+	if err := task.ExecuteTaskWithContext(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := task.Results()
+	if len(results) != 1 || results[0] != "value-suffix" {
+		t.Errorf("unexpected results, got: %#v, want: [value-suffix]", results)
+	}
+}
+
+func TestTask_ExecuteTaskWithContext_CancelsExplicitContext(t *testing.T) {
+	explicitCtx := context.WithValue(context.Background(), contextKey{}, "explicit")
+	task := NewTask(func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return ctx.Value(contextKey{}).(string), ctx.Err()
+	}, explicitCtx)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := task.ExecuteTaskWithContext(ctx); err != context.Canceled {
+		t.Errorf("unexpected error, got: %v, want: %v", err, context.Canceled)
+	}
+	results := task.Results()
+	if len(results) != 1 || results[0] != "explicit" {
+		t.Errorf("unexpected results, got: %#v, want: [explicit]", results)
+	}
+}
@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	gr_variable "github.com/vd09/gr-variable"
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/logger"
+)
+
+// WorkerFactory creates the workers of a pool. A worker reads its tasks from the channel until it is closed
+// and must call isEligibleToStop before returning; it may only return if the call returned true.
+type WorkerFactory interface {
+	NewWorker(ctx context.Context, tasks gr_variable.GrChannel[*gr_worker.Task], logger logger.Logger,
+		isEligibleToStop IsEligibleToStopFunc) Worker
+}
+
+// WorkerFactoryFunc allows to use a function as a WorkerFactory.
+type WorkerFactoryFunc func(ctx context.Context, tasks gr_variable.GrChannel[*gr_worker.Task], logger logger.Logger,
+	isEligibleToStop IsEligibleToStopFunc) Worker
+
+func (f WorkerFactoryFunc) NewWorker(ctx context.Context, tasks gr_variable.GrChannel[*gr_worker.Task], logger logger.Logger,
+	isEligibleToStop IsEligibleToStopFunc) Worker {
+	return f(ctx, tasks, logger, isEligibleToStop)
+}
+
+var (
+	// StandardWorkerFactory creates the workers of the STANDARD_WORKER strategy.
+	StandardWorkerFactory WorkerFactory = WorkerFactoryFunc(NewStandardWorker)
+	// SingleTaskWorkerFactory creates the workers of the SINGLE_TASK_WORKER strategy.
+	SingleTaskWorkerFactory WorkerFactory = WorkerFactoryFunc(NewSingleTaskWorker)
+)
+
+// IdealTimeoutWorkerFactory creates the workers of the IDEAL_WORKER_TIMEOUT strategy.
+func IdealTimeoutWorkerFactory(idleTimeout time.Duration) WorkerFactory {
+	return WorkerFactoryFunc(func(ctx context.Context, tasks gr_variable.GrChannel[*gr_worker.Task], logger logger.Logger,
+		isEligibleToStop IsEligibleToStopFunc) Worker {
+		return NewIdealTimeoutWorker(ctx, idleTimeout, logger, tasks, isEligibleToStop)
+	})
+}
+
+// NewWorkerFactory returns the built-in factory of the strategy, it returns false for an unknown strategy.
+func NewWorkerFactory(strategy WorkerStrategy, idleTimeout time.Duration) (WorkerFactory, bool) {
+	switch strategy {
+	case STANDARD_WORKER:
+		return StandardWorkerFactory, true
+	case IDEAL_WORKER_TIMEOUT:
+		return IdealTimeoutWorkerFactory(idleTimeout), true
+	case SINGLE_TASK_WORKER:
+		return SingleTaskWorkerFactory, true
+	}
+	return nil, false
+}
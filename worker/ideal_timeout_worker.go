@@ -39,9 +39,7 @@ func (itw *IdealTimeoutWorker) Start() {
 		case task, ok := <-itw.tasks.Receive():
 			switch {
 			case ok:
-				if err := task.ExecuteTask(); err != nil {
-					itw.logger.Printf("[ERROR] Function %#v return non nil result: %#v", task, err)
-				}
+				executeTask(itw.logger, task)
 				itw.timer = time.NewTimer(itw.idealTimeout)
 			case itw.isEligibleToStop(domain.ALL_TASKS_DONE):
 				return
@@ -21,6 +21,7 @@ type SingleTaskWorker struct {
 func (btw *SingleTaskWorker) Start() {
 	assignedTask, ok := btw.tasks.ReadValue()
 	if !ok {
+		btw.isEligibleToStop(domain.ALL_TASKS_DONE)
 		return
 	}
 
@@ -31,9 +32,7 @@ func (btw *SingleTaskWorker) Start() {
 				return
 			}
 		default:
-			if err := assignedTask.ExecuteTask(); err != nil {
-				btw.logger.Printf("[ERROR] Function %#v return non nil result: %#v", assignedTask, err)
-			}
+			executeTask(btw.logger, assignedTask)
 		}
 	}
 }
@@ -28,9 +28,7 @@ func (sw *StandardWorker) Start() {
 		case task, ok := <-sw.tasks.Receive():
 			switch {
 			case ok:
-				if err := task.ExecuteTask(); err != nil {
-					sw.logger.Printf("[ERROR] Function %#v return non nil result: %#v", task, err)
-				}
+				executeTask(sw.logger, task)
 			case sw.isEligibleToStop(domain.ALL_TASKS_DONE):
 				return
 			}
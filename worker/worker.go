@@ -1,9 +1,31 @@
 package worker
 
-import "github.com/vd09/gr_worker/domain"
+import (
+	"errors"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/domain"
+	"github.com/vd09/gr_worker/logger"
+)
 
 type IsEligibleToStopFunc func(domain.WorkerStatus) bool
 
 type Worker interface {
 	Start()
 }
+
+// executeTask runs the task and reports its failure through the logger.
+// Panics are already recovered by the task, so the calling worker always stays alive.
+func executeTask(logger logger.Logger, task *gr_worker.Task) {
+	err := task.ExecuteTask()
+	if err == nil {
+		return
+	}
+
+	var panicErr *gr_worker.PanicError
+	if errors.As(err, &panicErr) {
+		logger.Printf("[PANIC] Function %#v panicked: %v\n%s", task, panicErr.Value, panicErr.Stack)
+		return
+	}
+	logger.Printf("[ERROR] Function %#v return non nil result: %#v", task, err)
+}
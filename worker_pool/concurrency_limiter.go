@@ -0,0 +1,142 @@
+package worker_pool
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultLimiterWindow       = 20
+	DefaultLimiterMaxErrorRate = 0.1
+	DefaultLimiterBackoffRatio = 0.75
+)
+
+var (
+	ErrLimiterWindow       = errors.New("adaptive concurrency window can't be less than zero")
+	ErrLimiterLatency      = errors.New("adaptive concurrency latency target can't be less than zero")
+	ErrLimiterErrorRate    = errors.New("adaptive concurrency max error rate must be between zero and one")
+	ErrLimiterBackoffRatio = errors.New("adaptive concurrency backoff ratio must be between zero and one")
+)
+
+// AdaptiveConcurrency configures an AIMD limiter of the number of tasks executed concurrently by a pool.
+// The limit starts at the max workers of the pool and is evaluated after every Window finished tasks: it is
+// multiplied by BackoffRatio if the tasks of the window were too slow or failed too often, otherwise it grows
+// by one. The limit always stays between the min workers, at least one, and the max workers of the pool.
+// Zero values fall back to the defaults.
+type AdaptiveConcurrency struct {
+	// LatencyTarget is the highest average execution latency of a window which is not a congestion.
+	// The latency is ignored if it is zero.
+	LatencyTarget time.Duration
+	// MaxErrorRate is the highest rate of failed tasks of a window which is not a congestion.
+	MaxErrorRate float64
+	Window       int
+	BackoffRatio float64
+}
+
+func (ac *AdaptiveConcurrency) validate() error {
+	if ac.Window < 0 {
+		return ErrLimiterWindow
+	}
+	if ac.LatencyTarget < 0 {
+		return ErrLimiterLatency
+	}
+	if ac.MaxErrorRate < 0 || ac.MaxErrorRate > 1 {
+		return ErrLimiterErrorRate
+	}
+	if ac.BackoffRatio < 0 || ac.BackoffRatio >= 1 {
+		return ErrLimiterBackoffRatio
+	}
+	return nil
+}
+
+// concurrencyLimiter applies the AIMD algorithm of AdaptiveConcurrency.
+type concurrencyLimiter struct {
+	config AdaptiveConcurrency
+	limit  atomic.Int32
+
+	mutex      sync.Mutex
+	minLimit   int32
+	maxLimit   int32
+	samples    int
+	failures   int
+	latencySum time.Duration
+}
+
+func newConcurrencyLimiter(config AdaptiveConcurrency, minWorkers, maxWorkers int32) *concurrencyLimiter {
+	if config.Window == 0 {
+		config.Window = DefaultLimiterWindow
+	}
+	if config.MaxErrorRate == 0 {
+		config.MaxErrorRate = DefaultLimiterMaxErrorRate
+	}
+	if config.BackoffRatio == 0 {
+		config.BackoffRatio = DefaultLimiterBackoffRatio
+	}
+
+	cl := &concurrencyLimiter{config: config}
+	cl.limit.Store(maxWorkers)
+	cl.setBounds(minWorkers, maxWorkers)
+	return cl
+}
+
+// setBounds changes the range of the limit, the current limit is moved into the new range.
+func (cl *concurrencyLimiter) setBounds(minWorkers, maxWorkers int32) {
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+
+	cl.minLimit, cl.maxLimit = max(minWorkers, 1), maxWorkers
+	cl.limit.Store(min(max(cl.limit.Load(), cl.minLimit), cl.maxLimit))
+}
+
+// observe records a finished task and adjusts the limit once the window is complete.
+func (cl *concurrencyLimiter) observe(latency time.Duration, err error) {
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+
+	cl.samples++
+	cl.latencySum += latency
+	if err != nil {
+		cl.failures++
+	}
+	if cl.samples < cl.config.Window {
+		return
+	}
+
+	errorRate := float64(cl.failures) / float64(cl.samples)
+	averageLatency := cl.latencySum / time.Duration(cl.samples)
+	cl.samples, cl.failures, cl.latencySum = 0, 0, 0
+
+	limit := cl.limit.Load()
+	if errorRate > cl.config.MaxErrorRate || (cl.config.LatencyTarget > 0 && averageLatency > cl.config.LatencyTarget) {
+		limit = int32(math.Floor(float64(limit) * cl.config.BackoffRatio))
+	} else {
+		limit++
+	}
+	cl.limit.Store(min(max(limit, cl.minLimit), cl.maxLimit))
+}
+
+// concurrencyLimit returns the highest number of tasks the pool can execute concurrently.
+func (wp *WorkerPoolAdapter) concurrencyLimit() int32 {
+	if wp.limiter == nil {
+		return wp.workerLimit.Load()
+	}
+	return wp.limiter.limit.Load()
+}
+
+// hasTaskSlotLocked reports whether the dispatcher can hand one more task to the workers.
+func (wp *WorkerPoolAdapter) hasTaskSlotLocked() bool {
+	return wp.limiter == nil || int32(wp.tokensOutstanding+wp.executingTasks) < wp.limiter.limit.Load()
+}
+
+// releaseTaskSlot is called once the task popped by a queue token is finished.
+func (wp *WorkerPoolAdapter) releaseTaskSlot(future *Future) {
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+
+	wp.executingTasks--
+	wp.queue.finished(future)
+	wp.notifyQueueChangedLocked()
+}
@@ -0,0 +1,103 @@
+package worker_pool
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/logger"
+)
+
+func TestConcurrencyLimiter_Observe(t *testing.T) {
+	limiter := newConcurrencyLimiter(AdaptiveConcurrency{Window: 2, LatencyTarget: 10 * time.Millisecond, BackoffRatio: 0.5}, 2, 10)
+	steps := []struct {
+		name     string
+		latency  time.Duration
+		err      error
+		expected int32
+	}{
+		{"SlowTasks", 20 * time.Millisecond, nil, 5},
+		{"FailedTasks", time.Millisecond, errTemporary, 2},
+		{"MinLimit", time.Millisecond, errTemporary, 2},
+		{"HealthyTasks", time.Millisecond, nil, 3},
+	}
+
+	for _, step := range steps {
+		limiter.observe(step.latency, step.err)
+		limiter.observe(step.latency, step.err)
+		if limit := limiter.limit.Load(); limit != step.expected {
+			t.Errorf("%s: unexpected limit, got: %d, want: %d", step.name, limit, step.expected)
+		}
+	}
+
+	for i := 0; i < 40; i++ {
+		limiter.observe(time.Millisecond, nil)
+	}
+	if limit := limiter.limit.Load(); limit != 10 {
+		t.Errorf("limit is not capped by the max workers, got: %d", limit)
+	}
+}
+
+func TestWorkerPoolAdapter_WithAdaptiveConcurrency(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMinWorkers(1), WithMaxWorkers(4), WithMaxTasks(20), WithLogger(logger.Discard),
+		WithAdaptiveConcurrency(AdaptiveConcurrency{Window: 4, BackoffRatio: 0.5}))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	for i := 0; i < 4; i++ {
+		future, _ := wp.Submit(func() error { return errTemporary })
+		_ = future.Wait(testContext(t))
+	}
+	if limit := wp.Stats().ConcurrencyLimit; limit != 2 {
+		t.Fatalf("unexpected limit after failed tasks, got: %d, want: 2", limit)
+	}
+
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	futures := make([]*Future, 0, 8)
+	for i := 0; i < 8; i++ {
+		future, _ := wp.Submit(func() {
+			mutex.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mutex.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		})
+		futures = append(futures, future)
+	}
+	for _, future := range futures {
+		_ = future.Wait(testContext(t))
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if maxRunning > 3 {
+		t.Errorf("concurrency is not limited, got %d concurrent tasks", maxRunning)
+	}
+}
+
+func TestAdaptiveConcurrency_Validation(t *testing.T) {
+	tests := []struct {
+		config   AdaptiveConcurrency
+		expected error
+	}{
+		{AdaptiveConcurrency{Window: -1}, ErrLimiterWindow},
+		{AdaptiveConcurrency{LatencyTarget: -time.Second}, ErrLimiterLatency},
+		{AdaptiveConcurrency{MaxErrorRate: 2}, ErrLimiterErrorRate},
+		{AdaptiveConcurrency{BackoffRatio: 1}, ErrLimiterBackoffRatio},
+		{AdaptiveConcurrency{}, nil},
+	}
+
+	for _, test := range tests {
+		if err := test.config.validate(); err != test.expected {
+			t.Errorf("unexpected error for %+v, got: %v, want: %v", test.config, err, test.expected)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package worker_pool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/vd09/gr_worker"
+)
+
+// DeadLetterReason tells why a task is sent to the dead letter sink.
+type DeadLetterReason int
+
+const (
+	// RETRIES_EXHAUSTED is used for a task which failed on its last attempt allowed by the retry policy.
+	RETRIES_EXHAUSTED DeadLetterReason = iota
+	// TASK_PANICKED is used for a task which panicked and is not retried.
+	TASK_PANICKED
+	// TASK_DROPPED is used for a task which never ran or whose retry never ran as the pool was stopped,
+	// or which was dropped by the rejection policy of a full queue.
+	TASK_DROPPED
+)
+
+func (r DeadLetterReason) String() string {
+	switch r {
+	case RETRIES_EXHAUSTED:
+		return "retries_exhausted"
+	case TASK_PANICKED:
+		return "panicked"
+	case TASK_DROPPED:
+		return "dropped"
+	}
+	return fmt.Sprintf("DeadLetterReason(%d)", int(r))
+}
+
+// DeadLetter is a task which failed permanently. The task can be replayed by submitting its Func and Params again.
+type DeadLetter struct {
+	Task        *gr_worker.Task
+	Reason      DeadLetterReason
+	Err         error
+	Attempts    int
+	SubmittedAt time.Time
+	FailedAt    time.Time
+}
+
+// DeadLetterSink receives the dead letters of a pool, Send is called from the goroutine which gave up on the task.
+type DeadLetterSink interface {
+	Send(letter *DeadLetter) error
+}
+
+func (wp *WorkerPoolAdapter) sendDeadLetter(future *Future, reason DeadLetterReason, err error) {
+	if wp.deadLetterSink == nil {
+		return
+	}
+
+	letter := &DeadLetter{
+		Task:        future.Task(),
+		Reason:      reason,
+		Err:         err,
+		Attempts:    future.Attempts(),
+		SubmittedAt: future.submittedAt,
+		FailedAt:    time.Now(),
+	}
+	if sinkErr := wp.deadLetterSink.Send(letter); sinkErr != nil {
+		wp.logger.Printf("[ERROR] Dead letter of function %#v is not stored: %v", letter.Task.Func(), sinkErr)
+	}
+}
+
+// MemoryDeadLetterSink keeps the dead letters in memory. With a capacity, the oldest letters are discarded
+// once it is full.
+type MemoryDeadLetterSink struct {
+	mutex    sync.Mutex
+	capacity int
+	letters  []*DeadLetter
+}
+
+// NewMemoryDeadLetterSink creates an in-memory sink, a capacity lower than one keeps all the letters.
+func NewMemoryDeadLetterSink(capacity int) *MemoryDeadLetterSink {
+	return &MemoryDeadLetterSink{capacity: capacity}
+}
+
+func (ms *MemoryDeadLetterSink) Send(letter *DeadLetter) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if ms.capacity > 0 && len(ms.letters) >= ms.capacity {
+		ms.letters[0] = nil
+		ms.letters = ms.letters[1:]
+	}
+	ms.letters = append(ms.letters, letter)
+	return nil
+}
+
+// Letters returns the stored letters in the order they were received.
+func (ms *MemoryDeadLetterSink) Letters() []*DeadLetter {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return append([]*DeadLetter(nil), ms.letters...)
+}
+
+// Take removes and returns the stored letters, e.g. to replay them.
+func (ms *MemoryDeadLetterSink) Take() []*DeadLetter {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	letters := ms.letters
+	ms.letters = nil
+	return letters
+}
+
+func (ms *MemoryDeadLetterSink) Len() int {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return len(ms.letters)
+}
+
+// DeadLetterRecord is the JSON representation of a dead letter written by JSONLinesDeadLetterSink.
+// A parameter which can't be encoded as JSON is written as its Go syntax representation.
+type DeadLetterRecord struct {
+	Function    string            `json:"function"`
+	Params      []json.RawMessage `json:"params"`
+	Reason      string            `json:"reason"`
+	Error       string            `json:"error,omitempty"`
+	Attempts    int               `json:"attempts"`
+	SubmittedAt time.Time         `json:"submitted_at"`
+	FailedAt    time.Time         `json:"failed_at"`
+}
+
+// NewDeadLetterRecord converts a dead letter into its JSON representation.
+func NewDeadLetterRecord(letter *DeadLetter) *DeadLetterRecord {
+	record := &DeadLetterRecord{
+		Function:    functionName(letter.Task.Func()),
+		Params:      make([]json.RawMessage, 0, len(letter.Task.Params())),
+		Reason:      letter.Reason.String(),
+		Attempts:    letter.Attempts,
+		SubmittedAt: letter.SubmittedAt,
+		FailedAt:    letter.FailedAt,
+	}
+	if letter.Err != nil {
+		record.Error = letter.Err.Error()
+	}
+	for _, param := range letter.Task.Params() {
+		encoded, err := json.Marshal(param)
+		if err != nil {
+			encoded, _ = json.Marshal(fmt.Sprintf("%#v", param))
+		}
+		record.Params = append(record.Params, encoded)
+	}
+	return record
+}
+
+func functionName(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", fn)
+	}
+	if function := runtime.FuncForPC(value.Pointer()); function != nil {
+		return function.Name()
+	}
+	return value.Type().String()
+}
+
+// JSONLinesDeadLetterSink writes every dead letter as a DeadLetterRecord on its own line.
+type JSONLinesDeadLetterSink struct {
+	mutex   sync.Mutex
+	writer  io.Writer
+	encoder *json.Encoder
+}
+
+func NewJSONLinesDeadLetterSink(writer io.Writer) *JSONLinesDeadLetterSink {
+	return &JSONLinesDeadLetterSink{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// NewFileDeadLetterSink appends the dead letters to the file, it is created if required.
+func NewFileDeadLetterSink(path string) (*JSONLinesDeadLetterSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONLinesDeadLetterSink(file), nil
+}
+
+func (js *JSONLinesDeadLetterSink) Send(letter *DeadLetter) error {
+	record := NewDeadLetterRecord(letter)
+
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+	return js.encoder.Encode(record)
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (js *JSONLinesDeadLetterSink) Close() error {
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+
+	if closer, ok := js.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package worker_pool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/logger"
+)
+
+func TestWorkerPoolAdapter_DeadLetterSink(t *testing.T) {
+	sink := NewMemoryDeadLetterSink(0)
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(2), WithLogger(logger.Discard), WithDeadLetterSink(sink))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	retried, _ := wp.SubmitWithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}, func() error { return errTemporary })
+	_ = retried.Wait(testContext(t))
+	panicked, _ := wp.Submit(func() { panic("boom") })
+	_ = panicked.Wait(testContext(t))
+	failed, _ := wp.Submit(func() error { return errTemporary })
+	_ = failed.Wait(testContext(t))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	wp.AddTask(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	wp.AddTask(func() {})
+	wp.Stop()
+	close(release)
+
+	letters := sink.Letters()
+	if len(letters) != 3 {
+		t.Fatalf("unexpected dead letters, got: %d, want: 3", len(letters))
+	}
+
+	expected := []struct {
+		reason   DeadLetterReason
+		attempts int
+	}{
+		{RETRIES_EXHAUSTED, 2},
+		{TASK_PANICKED, 1},
+		{TASK_DROPPED, 0},
+	}
+	for i, letter := range letters {
+		if letter.Reason != expected[i].reason || letter.Attempts != expected[i].attempts {
+			t.Errorf("unexpected dead letter %d, got: %v after %d attempts, want: %v after %d attempts",
+				i, letter.Reason, letter.Attempts, expected[i].reason, expected[i].attempts)
+		}
+		if letter.FailedAt.Before(letter.SubmittedAt) {
+			t.Errorf("dead letter %d failed before its submission", i)
+		}
+	}
+	var panicErr *gr_worker.PanicError
+	if !errors.As(letters[1].Err, &panicErr) {
+		t.Errorf("unexpected error of the panicked task: %v", letters[1].Err)
+	}
+	if letters[2].Err != ErrTaskDropped {
+		t.Errorf("unexpected error of the dropped task, got: %v, want: %v", letters[2].Err, ErrTaskDropped)
+	}
+}
+
+func TestMemoryDeadLetterSink_Capacity(t *testing.T) {
+	sink := NewMemoryDeadLetterSink(2)
+	for i := 0; i < 3; i++ {
+		_ = sink.Send(&DeadLetter{Attempts: i})
+	}
+
+	letters := sink.Take()
+	if len(letters) != 2 || letters[0].Attempts != 1 || letters[1].Attempts != 2 {
+		t.Errorf("oldest dead letter is not discarded: %+v", letters)
+	}
+	if sink.Len() != 0 {
+		t.Errorf("dead letters are not removed by Take, got: %d", sink.Len())
+	}
+}
+
+func TestJSONLinesDeadLetterSink(t *testing.T) {
+	var buffer bytes.Buffer
+	sink := NewJSONLinesDeadLetterSink(&buffer)
+	letter := &DeadLetter{
+		Task:     gr_worker.NewTask(func(name string, ch chan int) {}, "job", make(chan int)),
+		Reason:   RETRIES_EXHAUSTED,
+		Err:      errTemporary,
+		Attempts: 3,
+	}
+	for i := 0; i < 2; i++ {
+		if err := sink.Send(letter); err != nil {
+			t.Fatalf("error sending dead letter: %v", err)
+		}
+	}
+
+	scanner := bufio.NewScanner(&buffer)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		var record DeadLetterRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("error decoding record: %v", err)
+		}
+		if record.Reason != "retries_exhausted" || record.Error != errTemporary.Error() || record.Attempts != 3 {
+			t.Errorf("unexpected record: %+v", record)
+		}
+		if len(record.Params) != 2 || string(record.Params[0]) != `"job"` {
+			t.Errorf("unexpected params: %s", record.Params)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("unexpected lines, got: %d, want: 2", lines)
+	}
+}
+
+func TestFileDeadLetterSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead_letters.jsonl")
+	sink, err := NewFileDeadLetterSink(path)
+	if err != nil {
+		t.Fatalf("error creating sink: %v", err)
+	}
+	_ = sink.Send(&DeadLetter{Task: gr_worker.NewTask(func() {}), Reason: TASK_DROPPED, Err: ErrTaskDropped})
+	if err := sink.Close(); err != nil {
+		t.Fatalf("error closing sink: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading file: %v", err)
+	}
+	if !bytes.Contains(content, []byte(`"reason":"dropped"`)) {
+		t.Errorf("dead letter is not written: %s", content)
+	}
+}
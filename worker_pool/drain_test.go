@@ -0,0 +1,76 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolAdapter_Drain_ExecutesQueuedTasks(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(2), WithMaxTasks(4))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	var count atomic.Int32
+	for i := 0; i < 4; i++ {
+		wp.AddTask(func() { count.Add(1) })
+	}
+
+	notExecuted, err := wp.Drain(context.Background())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(notExecuted) != 0 || count.Load() != 4 {
+		t.Errorf("queued tasks are not executed; executed: %d, not executed: %d", count.Load(), len(notExecuted))
+	}
+	if wp.AddTask(func() {}) {
+		t.Error("task added to a drained worker pool")
+	}
+}
+
+func TestWorkerPoolAdapter_Drain_ReturnsNotExecutedTasks(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(3))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	wp.AddTask(func() { <-release })
+	time.Sleep(10 * time.Millisecond)
+
+	queuedFunc := func(value int) int { return value }
+	futures := make([]*Future, 0, 2)
+	for i := 1; i <= 2; i++ {
+		future, err := wp.Submit(queuedFunc, i)
+		if err != nil {
+			t.Fatalf("error submitting task: %v", err)
+		}
+		futures = append(futures, future)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	notExecuted, err := wp.Drain(ctx)
+
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) || shutdownErr.Queued != 2 || shutdownErr.Running != 1 {
+		t.Errorf("unexpected error: %#v", err)
+	}
+	if len(notExecuted) != 2 {
+		t.Fatalf("unexpected not executed tasks; got: %d, want: 2", len(notExecuted))
+	}
+	for i, task := range notExecuted {
+		if params := task.Params(); len(params) != 1 || params[0] != i+1 {
+			t.Errorf("not executed tasks are not in submission order; params: %#v", params)
+		}
+	}
+	for _, future := range futures {
+		if _, err := future.Result(); err != ErrTaskDropped {
+			t.Errorf("unexpected future error, got: %v, want: %v", err, ErrTaskDropped)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vd09/gr_worker"
+)
+
+var (
+	ErrTaskCancelled = errors.New("task cancelled before execution")
+	ErrTaskDropped   = errors.New("task dropped while stopping the worker pool")
+)
+
+type futureState int32
+
+const (
+	futurePending futureState = iota
+	futureRunning
+	futureDone
+	futureCancelled
+)
+
+// Future is the handle of a task submitted to a worker pool.
+// It allows to wait for the task and read the values returned by the task function.
+type Future struct {
+	task        *gr_worker.Task
+	seq         uint64
+	priority    int
+	tenant      string
+	keyed       bool
+	submittedAt time.Time
+	enqueuedAt  time.Time
+
+	// Retries of the task, lastBackoff is only accessed by the worker executing the task.
+	// lastErr is the error of the last attempt of a task waiting for its retry.
+	retryPolicy     *RetryPolicy
+	attempts        atomic.Int32
+	lastBackoff     time.Duration
+	lastErr         error
+	cancelRequested atomic.Bool
+
+	state    atomic.Int32
+	done     chan struct{}
+	complete sync.Once
+
+	mutex      sync.Mutex
+	cancelTask context.CancelFunc
+	onCancel   func()
+	onFinish   func()
+
+	results []interface{}
+	err     error
+}
+
+func newFuture(task *gr_worker.Task) *Future {
+	return &Future{
+		task:        task,
+		submittedAt: time.Now(),
+		done:        make(chan struct{}),
+	}
+}
+
+// Done returns a channel which is closed once the task is finished or cancelled.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the task is finished or the context is done.
+// It returns the error of the task function, or the context error if the context finished first.
+func (f *Future) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Result blocks until the task is finished and returns the values returned by the task function.
+// A trailing error return value of the task function is returned as the error.
+func (f *Future) Result() ([]interface{}, error) {
+	<-f.done
+	return f.results, f.err
+}
+
+// Cancel removes a task which is not yet picked by any worker or which is waiting for a retry.
+// It returns false if the task is running or finished, the context of a running task is cancelled
+// and the task is not retried anymore.
+func (f *Future) Cancel() bool {
+	if !f.drop(ErrTaskCancelled) {
+		f.mutex.Lock()
+		f.cancelRequested.Store(true)
+		if f.cancelTask != nil {
+			f.cancelTask()
+		}
+		f.mutex.Unlock()
+
+		// The task may have been moved back to pending for a retry before the cancel request was seen.
+		if !f.drop(ErrTaskCancelled) {
+			return false
+		}
+	}
+
+	f.mutex.Lock()
+	onCancel := f.onCancel
+	f.mutex.Unlock()
+	if onCancel != nil {
+		onCancel()
+	}
+	return true
+}
+
+// IsCancelled reports whether the task is cancelled or dropped before its execution.
+func (f *Future) IsCancelled() bool {
+	return futureState(f.state.Load()) == futureCancelled
+}
+
+// Attempts returns the number of times the task has been executed.
+func (f *Future) Attempts() int {
+	return int(f.attempts.Load())
+}
+
+// Task returns the task wrapped by the future.
+func (f *Future) Task() *gr_worker.Task {
+	return f.task
+}
+
+// start marks a pending future as running and returns the state in which the task is picked by the worker.
+// A finished future is picked again as SINGLE_TASK_WORKER executes the same task repeatedly.
+func (f *Future) start() futureState {
+	if f.state.CompareAndSwap(int32(futurePending), int32(futureRunning)) {
+		return futureRunning
+	}
+	return futureState(f.state.Load())
+}
+
+// drop finishes a pending future with the given error, the task is never executed afterwards.
+func (f *Future) drop(err error) bool {
+	if !f.state.CompareAndSwap(int32(futurePending), int32(futureCancelled)) {
+		return false
+	}
+	f.finish(nil, err)
+	return true
+}
+
+// retry moves a running future back to pending, so it can be queued again.
+func (f *Future) retry() bool {
+	return f.state.CompareAndSwap(int32(futureRunning), int32(futurePending))
+}
+
+func (f *Future) setOnCancel(onCancel func()) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.onCancel = onCancel
+}
+
+// setOnFinish registers a callback called once the future is finished,
+// it returns false if the future is already finished.
+func (f *Future) setOnFinish(onFinish func()) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	select {
+	case <-f.done:
+		return false
+	default:
+		f.onFinish = onFinish
+		return true
+	}
+}
+
+func (f *Future) setCancelFunc(cancel context.CancelFunc) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.cancelTask = cancel
+}
+
+func (f *Future) finish(results []interface{}, err error) {
+	f.complete.Do(func() {
+		f.results = results
+		f.err = err
+		f.state.CompareAndSwap(int32(futureRunning), int32(futureDone))
+		close(f.done)
+
+		f.mutex.Lock()
+		onFinish := f.onFinish
+		f.mutex.Unlock()
+		if onFinish != nil {
+			onFinish()
+		}
+	})
+}
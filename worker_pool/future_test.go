@@ -0,0 +1,92 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolAdapter_Submit_Result(t *testing.T) {
+	wp, err := NewWorkerPool(
+		WithMaxWorkers(2),
+		WithMaxTasks(2),
+	)
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	expectedErr := errors.New("task failed")
+	future, err := wp.Submit(func(a, b int) (int, error) { return a + b, expectedErr }, 2, 3)
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := future.Wait(ctx); err != expectedErr {
+		t.Errorf("unexpected error, got: %v, want: %v", err, expectedErr)
+	}
+
+	results, err := future.Result()
+	if err != expectedErr {
+		t.Errorf("unexpected error, got: %v, want: %v", err, expectedErr)
+	}
+	if len(results) != 1 || results[0] != 5 {
+		t.Errorf("unexpected results, got: %#v, want: [5]", results)
+	}
+	select {
+	case <-future.Done():
+	default:
+		t.Error("done channel is not closed after the task finished")
+	}
+}
+
+func TestWorkerPoolAdapter_Submit_Cancel(t *testing.T) {
+	wp, err := NewWorkerPool(
+		WithMaxWorkers(1),
+		WithMaxTasks(2),
+	)
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	release := make(chan struct{})
+	if _, err := wp.Submit(func() { <-release }); err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+
+	executed := false
+	future, err := wp.Submit(func() { executed = true })
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+	if !future.Cancel() {
+		t.Error("pending task is not cancelled")
+	}
+	if _, err := future.Result(); err != ErrTaskCancelled {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrTaskCancelled)
+	}
+
+	close(release)
+	wp.WaitAndStop()
+	if executed {
+		t.Error("cancelled task is executed")
+	}
+	if future.Cancel() {
+		t.Error("cancelled task is cancelled twice")
+	}
+}
+
+func TestWorkerPoolAdapter_Submit_Stopped(t *testing.T) {
+	wp, err := NewWorkerPool()
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	wp.Stop()
+
+	if _, err := wp.Submit(func() {}); err != ErrWorkerPoolStopped {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrWorkerPoolStopped)
+	}
+}
@@ -0,0 +1,100 @@
+package worker_pool
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vd09/gr_worker"
+)
+
+// Group runs a set of related tasks on the workers of a pool and waits for all of them, like errgroup.
+// The zero value is not usable, a Group is created with WorkerPoolAdapter.NewGroup.
+type Group struct {
+	pool          *WorkerPoolAdapter
+	ctx           context.Context
+	cancel        context.CancelFunc
+	cancelOnError bool
+	slots         chan struct{}
+
+	tasks   sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+type GroupOption func(*Group)
+
+// CancelOnError cancels the context of the group on the first failed task, so the queued tasks of the group
+// are dropped and the running ones see their context cancelled
+func WithCancelOnError() GroupOption {
+	return func(g *Group) {
+		g.cancelOnError = true
+	}
+}
+
+// GroupLimit allows to limit the number of unfinished tasks of the group, on top of the max workers of the pool.
+// A limit lower than one means no limit
+func WithGroupLimit(limit int) GroupOption {
+	return func(g *Group) {
+		if limit > 0 {
+			g.slots = make(chan struct{}, limit)
+		}
+	}
+}
+
+// NewGroup creates a group of tasks executed by the pool. The tasks of the group receive a context derived from
+// the given context, it is cancelled once Wait returns.
+func (wp *WorkerPoolAdapter) NewGroup(ctx context.Context, options ...GroupOption) *Group {
+	groupCtx, cancel := context.WithCancel(ctx)
+	g := &Group{
+		pool:   wp,
+		ctx:    groupCtx,
+		cancel: cancel,
+	}
+	for _, opt := range options {
+		opt(g)
+	}
+	return g
+}
+
+// Context returns the context of the group, it is cancelled on the first error with WithCancelOnError.
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go adds a task to the group. It blocks while the group limit is reached, then it submits the task
+// to the pool like SubmitWithContext with the context of the group. A task which can't be submitted fails
+// the group with the error of the submission.
+func (g *Group) Go(taskFunc interface{}, params ...interface{}) {
+	if g.slots != nil {
+		g.slots <- struct{}{}
+	}
+	g.tasks.Add(1)
+
+	future := newFuture(gr_worker.NewTaskWithContext(g.ctx, taskFunc, params...))
+	future.setOnFinish(func() { g.finish(future.err) })
+	if _, err := g.pool.submitFuture(future); err != nil {
+		g.finish(err)
+	}
+}
+
+// Wait blocks until all the tasks of the group are finished and returns the first error of the tasks.
+func (g *Group) Wait() error {
+	g.tasks.Wait()
+	g.cancel()
+	return g.err
+}
+
+func (g *Group) finish(err error) {
+	if err != nil {
+		g.errOnce.Do(func() {
+			g.err = err
+			if g.cancelOnError {
+				g.cancel()
+			}
+		})
+	}
+	if g.slots != nil {
+		<-g.slots
+	}
+	g.tasks.Done()
+}
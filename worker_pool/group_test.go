@@ -0,0 +1,118 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/logger"
+)
+
+func TestGroup_Wait(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(4), WithMaxTasks(10), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	var sum atomic.Int64
+	group := wp.NewGroup(context.Background())
+	for i := 1; i <= 20; i++ {
+		group.Go(func(value int) { sum.Add(int64(value)) }, i)
+	}
+	if err := group.Wait(); err != nil {
+		t.Fatalf("unexpected group error: %v", err)
+	}
+	if sum.Load() != 210 {
+		t.Errorf("not all the tasks of the group are executed, sum: %d", sum.Load())
+	}
+	if group.Context().Err() == nil {
+		t.Error("group context is not cancelled after Wait")
+	}
+}
+
+func TestGroup_CancelOnError(t *testing.T) {
+	tests := []struct {
+		name            string
+		options         []GroupOption
+		expectCancelled bool
+	}{
+		{"CancelOnError", []GroupOption{WithCancelOnError()}, true},
+		{"KeepRunning", nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(10), WithLogger(logger.Discard))
+			if err != nil {
+				t.Fatalf("error creating worker pool: %v", err)
+			}
+			defer wp.Stop()
+
+			var executed atomic.Int32
+			group := wp.NewGroup(context.Background(), test.options...)
+			group.Go(func() error { return errTemporary })
+			for i := 0; i < 3; i++ {
+				group.Go(func(ctx context.Context) { executed.Add(1) })
+			}
+
+			if err := group.Wait(); err != errTemporary {
+				t.Errorf("unexpected group error, got: %v, want: %v", err, errTemporary)
+			}
+			if cancelled := executed.Load() == 0; cancelled != test.expectCancelled {
+				t.Errorf("unexpected executed siblings: %d", executed.Load())
+			}
+		})
+	}
+}
+
+func TestGroup_Limit(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(4), WithMaxTasks(10), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	group := wp.NewGroup(context.Background(), WithGroupLimit(2))
+	for i := 0; i < 8; i++ {
+		group.Go(func() {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+
+			time.Sleep(2 * time.Millisecond)
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		})
+	}
+	if err := group.Wait(); err != nil {
+		t.Fatalf("unexpected group error: %v", err)
+	}
+	if maxRunning != 2 {
+		t.Errorf("unexpected concurrency of the group, got: %d, want: 2", maxRunning)
+	}
+}
+
+func TestGroup_SubmitError(t *testing.T) {
+	wp, err := NewWorkerPool(WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	wp.Stop()
+
+	group := wp.NewGroup(context.Background())
+	group.Go(func() {})
+	if err := group.Wait(); !errors.Is(err, ErrWorkerPoolStopped) {
+		t.Errorf("unexpected group error, got: %v, want: %v", err, ErrWorkerPoolStopped)
+	}
+}
@@ -0,0 +1,58 @@
+package worker_pool
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+const latencyBucketCount = 11
+
+// latencyBuckets are the upper bounds of the latency histograms of a pool.
+var latencyBuckets = [latencyBucketCount]time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram is a snapshot of a latency distribution. Counts[i] is the number of observations greater than
+// Bounds[i-1] and lower than or equal to Bounds[i], the last count holds the observations above all the bounds.
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// latencyHistogram counts latencies in fixed buckets with atomic operations, its zero value is ready to use.
+type latencyHistogram struct {
+	counts [latencyBucketCount + 1]atomic.Uint64
+	sum    atomic.Int64
+}
+
+func (lh *latencyHistogram) observe(latency time.Duration) {
+	bucket := sort.Search(latencyBucketCount, func(i int) bool { return latency <= latencyBuckets[i] })
+	lh.counts[bucket].Add(1)
+	lh.sum.Add(int64(latency))
+}
+
+func (lh *latencyHistogram) snapshot() Histogram {
+	histogram := Histogram{
+		Bounds: append([]time.Duration(nil), latencyBuckets[:]...),
+		Counts: make([]uint64, len(lh.counts)),
+		Sum:    time.Duration(lh.sum.Load()),
+	}
+	for i := range lh.counts {
+		histogram.Counts[i] = lh.counts[i].Load()
+		histogram.Count += histogram.Counts[i]
+	}
+	return histogram
+}
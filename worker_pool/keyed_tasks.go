@@ -0,0 +1,223 @@
+package worker_pool
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/vd09/gr_worker"
+)
+
+const DefaultMaxTasksPerKey = 16
+
+// keyedQueue holds the tasks of a key waiting for the task of the same key which is in the pool.
+type keyedQueue struct {
+	pending []*Future
+	space   chan struct{}
+}
+
+// keyedTasks runs the tasks sharing a key one at a time in submission order. Only the head task of every key
+// is in the task queue of the pool, the next one is added once the head is finished. A key is removed as soon
+// as it has no task left. The waiting tasks are counted as queued tasks of the pool, so a drained pool runs them.
+type keyedTasks struct {
+	pool *WorkerPoolAdapter
+
+	mutex   sync.Mutex
+	queues  map[string]*keyedQueue
+	closed  bool
+	stopped bool
+}
+
+func newKeyedTasks(pool *WorkerPoolAdapter) *keyedTasks {
+	return &keyedTasks{
+		pool:   pool,
+		queues: make(map[string]*keyedQueue),
+	}
+}
+
+// submit adds the future behind the tasks of its key, waiting while the key has maxTasksPerKey waiting tasks.
+func (kt *keyedTasks) submit(key string, future *Future) error {
+	kt.mutex.Lock()
+	for {
+		if kt.closed || kt.stopped {
+			kt.mutex.Unlock()
+			return ErrWorkerPoolStopped
+		}
+
+		queue, ok := kt.queues[key]
+		if !ok {
+			kt.queues[key] = &keyedQueue{}
+			kt.mutex.Unlock()
+			return kt.run(key, future, false)
+		}
+		if len(queue.pending) < int(kt.pool.maxTasksPerKey) {
+			future.keyed = true
+			queue.pending = append(queue.pending, future)
+			kt.pool.taskTracker.add(future)
+			kt.pool.addKeyedWaiting(1)
+			future.setOnCancel(func() { kt.remove(key, future) })
+			kt.mutex.Unlock()
+			return nil
+		}
+
+		if queue.space == nil {
+			queue.space = make(chan struct{})
+		}
+		space := queue.space
+		kt.mutex.Unlock()
+		select {
+		case <-space:
+		case <-kt.pool.ctx.Done():
+			return ErrWorkerPoolStopped
+		}
+		kt.mutex.Lock()
+	}
+}
+
+// run adds the head task of the key to the pool, the next task of the key is started once it is finished.
+// A submitted task is subject to the rejection policy of the pool, a task which was waiting behind its key
+// is already accepted and blocks until there is space.
+func (kt *keyedTasks) run(key string, future *Future, waiting bool) error {
+	if !future.setOnFinish(func() { go kt.next(key) }) {
+		kt.next(key)
+		return nil
+	}
+
+	var err error
+	if waiting {
+		err = kt.pool.writeTask(future, BLOCK)
+	} else {
+		err = kt.pool.submitWithRejectionPolicy(future)
+	}
+	if err != nil {
+		// The future is not finished, the next task of the key has to be started here.
+		future.setOnFinish(nil)
+		kt.next(key)
+	}
+	return err
+}
+
+// next starts the next task of the key, tasks which can't be added to the pool are dropped.
+func (kt *keyedTasks) next(key string) {
+	kt.mutex.Lock()
+	queue, ok := kt.queues[key]
+	if !ok {
+		kt.mutex.Unlock()
+		return
+	}
+	if len(queue.pending) == 0 {
+		delete(kt.queues, key)
+		kt.notifySpaceLocked(queue)
+		kt.mutex.Unlock()
+		return
+	}
+
+	future := queue.pending[0]
+	queue.pending[0] = nil
+	queue.pending = queue.pending[1:]
+	kt.notifySpaceLocked(queue)
+	kt.mutex.Unlock()
+
+	future.setOnCancel(nil)
+	err := kt.run(key, future, true)
+	if err != nil {
+		kt.pool.taskTracker.remove(future)
+		kt.pool.dropFutures([]*Future{future})
+	}
+	// The task is in the task queue or dropped, it isn't waiting anymore.
+	kt.pool.addKeyedWaiting(-1)
+}
+
+// remove deletes a cancelled task which is waiting behind the tasks of its key.
+func (kt *keyedTasks) remove(key string, future *Future) {
+	kt.mutex.Lock()
+	defer kt.mutex.Unlock()
+
+	queue, ok := kt.queues[key]
+	if !ok {
+		return
+	}
+	for i, pending := range queue.pending {
+		if pending == future {
+			queue.pending = append(queue.pending[:i], queue.pending[i+1:]...)
+			kt.notifySpaceLocked(queue)
+			kt.pool.taskTracker.remove(future)
+			kt.pool.addKeyedWaiting(-1)
+			return
+		}
+	}
+}
+
+// close rejects new tasks, the waiting tasks are still executed.
+func (kt *keyedTasks) close() {
+	kt.mutex.Lock()
+	defer kt.mutex.Unlock()
+
+	kt.closed = true
+	for _, queue := range kt.queues {
+		kt.notifySpaceLocked(queue)
+	}
+}
+
+// stop rejects new tasks and returns the waiting tasks of all the keys in submission order.
+func (kt *keyedTasks) stop() []*Future {
+	kt.mutex.Lock()
+	defer kt.mutex.Unlock()
+
+	kt.stopped = true
+	var futures []*Future
+	for key, queue := range kt.queues {
+		futures = append(futures, queue.pending...)
+		kt.notifySpaceLocked(queue)
+		delete(kt.queues, key)
+	}
+	sort.SliceStable(futures, func(i, j int) bool { return futures[i].seq < futures[j].seq })
+	for _, future := range futures {
+		kt.pool.taskTracker.remove(future)
+	}
+	kt.pool.addKeyedWaiting(-len(futures))
+	return futures
+}
+
+// len returns the number of keys with a task in the pool or waiting.
+func (kt *keyedTasks) len() int {
+	kt.mutex.Lock()
+	defer kt.mutex.Unlock()
+	return len(kt.queues)
+}
+
+func (kt *keyedTasks) notifySpaceLocked(queue *keyedQueue) {
+	if queue.space != nil {
+		close(queue.space)
+		queue.space = nil
+	}
+}
+
+func (wp *WorkerPoolAdapter) AddTaskWithKey(key string, taskFunc interface{}, params ...interface{}) bool {
+	_, err := wp.SubmitWithKey(key, taskFunc, params...)
+	return err == nil
+}
+
+// SubmitWithKey adds a task which runs after all the tasks previously submitted with the same key are finished,
+// tasks with different keys run concurrently. A task without tasks of its key ahead is added like Submit,
+// applying the rejection policy of the pool. At most the configured max tasks per key wait behind a key,
+// the call blocks until there is space. Tasks waiting behind their key are counted as queued tasks: Drain
+// and WaitAndStop execute them, Stop drops them.
+func (wp *WorkerPoolAdapter) SubmitWithKey(key string, taskFunc interface{}, params ...interface{}) (*Future, error) {
+	if wp.IsWorkerPoolStopped() {
+		wp.counters.taskSubmitted(ErrWorkerPoolStopped)
+		return nil, ErrWorkerPoolStopped
+	}
+
+	future := newFuture(gr_worker.NewTask(taskFunc, params...))
+	err := wp.keyedTasks.submit(key, future)
+	wp.counters.taskSubmitted(err)
+	if err != nil {
+		return nil, err
+	}
+	return future, nil
+}
+
+// dropKeyedTasks finishes the futures of the tasks waiting behind their key with ErrTaskDropped.
+func (wp *WorkerPoolAdapter) dropKeyedTasks() []*gr_worker.Task {
+	return wp.dropFutures(wp.keyedTasks.stop())
+}
@@ -0,0 +1,267 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/logger"
+)
+
+func TestWorkerPoolAdapter_SubmitWithKey(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(4), WithMaxTasks(10), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	var mutex sync.Mutex
+	order := make(map[string][]int)
+	running := make(map[string]int)
+	overlapped := false
+	task := func(key string, i int) {
+		mutex.Lock()
+		running[key]++
+		if running[key] > 1 {
+			overlapped = true
+		}
+		mutex.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mutex.Lock()
+		running[key]--
+		order[key] = append(order[key], i)
+		mutex.Unlock()
+	}
+
+	var futures []*Future
+	for i := 0; i < 20; i++ {
+		for _, key := range []string{"a", "b"} {
+			future, err := wp.SubmitWithKey(key, task, key, i)
+			if err != nil {
+				t.Fatalf("error submitting task: %v", err)
+			}
+			futures = append(futures, future)
+		}
+	}
+	for _, future := range futures {
+		if err := future.Wait(testContext(t)); err != nil {
+			t.Fatalf("unexpected task error: %v", err)
+		}
+	}
+
+	if overlapped {
+		t.Error("tasks with the same key ran concurrently")
+	}
+	for key, executed := range order {
+		for i, value := range executed {
+			if value != i {
+				t.Errorf("tasks of key %q are not executed in submission order: %v", key, executed)
+				break
+			}
+		}
+	}
+	waitForCondition(t, func() bool { return wp.keyedTasks.len() == 0 }, "keys are not removed")
+}
+
+func TestWorkerPoolAdapter_SubmitWithKeyParallel(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(2), WithMaxTasks(2), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	for _, key := range []string{"a", "b"} {
+		wp.AddTaskWithKey(key, func() {
+			started <- struct{}{}
+			<-release
+		})
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("tasks with different keys are not executed concurrently")
+		}
+	}
+	close(release)
+}
+
+func TestWorkerPoolAdapter_SubmitWithKeyCancel(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(2), WithMaxTasksPerKey(1), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	head, _ := wp.SubmitWithKey("key", func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	cancelled, _ := wp.SubmitWithKey("key", func() { t.Error("cancelled task is executed") })
+	if !cancelled.Cancel() {
+		t.Fatal("waiting keyed task is not cancelled")
+	}
+
+	// The cancelled task frees the space of the key.
+	last, err := wp.SubmitWithKey("key", func() {})
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+	close(release)
+
+	if err := head.Wait(testContext(t)); err != nil {
+		t.Errorf("unexpected head task error: %v", err)
+	}
+	if err := last.Wait(testContext(t)); err != nil {
+		t.Errorf("unexpected last task error: %v", err)
+	}
+	if err := cancelled.Wait(testContext(t)); err != ErrTaskCancelled {
+		t.Errorf("unexpected cancelled task error, got: %v, want: %v", err, ErrTaskCancelled)
+	}
+}
+
+func TestWorkerPoolAdapter_SubmitWithKeyStop(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(2), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	wp.AddTaskWithKey("key", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	waiting, _ := wp.SubmitWithKey("key", func() { t.Error("dropped task is executed") })
+
+	wp.Stop()
+	close(release)
+
+	if err := waiting.Wait(testContext(t)); err != ErrTaskDropped {
+		t.Errorf("unexpected error of the waiting task, got: %v, want: %v", err, ErrTaskDropped)
+	}
+	if _, err := wp.SubmitWithKey("key", func() {}); err != ErrWorkerPoolStopped {
+		t.Errorf("unexpected error after stop, got: %v, want: %v", err, ErrWorkerPoolStopped)
+	}
+}
+
+func TestWorkerPoolAdapter_SubmitWithKeyWaitAndStop(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(2), WithMaxTasks(2), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	var mutex sync.Mutex
+	var order []int
+	for i := 0; i < 5; i++ {
+		wp.AddTaskWithKey("key", func(i int) {
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, i)
+		}, i)
+	}
+	wp.WaitAndStop()
+
+	if len(order) != 5 {
+		t.Fatalf("waiting keyed tasks are not executed by WaitAndStop: %v", order)
+	}
+	for i, value := range order {
+		if value != i {
+			t.Fatalf("keyed tasks are not executed in submission order: %v", order)
+		}
+	}
+	if wp.AddTaskWithKey("key", func() {}) {
+		t.Error("keyed task is accepted after WaitAndStop")
+	}
+}
+
+func TestWorkerPoolAdapter_SubmitWithKeyDrainTimeout(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(1), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	wp.AddTaskWithKey("key", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	waiting, _ := wp.SubmitWithKey("key", func() { t.Error("task is executed after the drain timeout") })
+	if queued := wp.Stats().QueuedTasks; queued != 1 {
+		t.Errorf("unexpected queued tasks, got: %d, want: 1", queued)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	notExecuted, err := wp.Drain(ctx)
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) || shutdownErr.Queued != 1 {
+		t.Errorf("unexpected drain error: %v", err)
+	}
+	if len(notExecuted) != 1 || notExecuted[0] != waiting.Task() {
+		t.Errorf("waiting keyed task is not returned: %v", notExecuted)
+	}
+	if err := waiting.Wait(testContext(t)); err != ErrTaskDropped {
+		t.Errorf("unexpected error of the waiting task, got: %v, want: %v", err, ErrTaskDropped)
+	}
+}
+
+func TestWorkerPoolAdapter_SubmitWithKeyRejected(t *testing.T) {
+	var rejections []RejectionPolicy
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(1), WithRejectionPolicy(REJECT),
+		WithLogger(logger.Discard), WithHooks(Hooks{OnTaskRejected: func(task *gr_worker.Task, policy RejectionPolicy) {
+			rejections = append(rejections, policy)
+		}}))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	wp.AddTask(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	wp.AddTask(func() {})
+
+	submitted := make(chan error, 1)
+	go func() {
+		_, err := wp.SubmitWithKey("key", func() { t.Error("rejected task is executed") })
+		submitted <- err
+	}()
+	select {
+	case err := <-submitted:
+		if err != ErrQueueFull {
+			t.Errorf("unexpected error, got: %v, want: %v", err, ErrQueueFull)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("keyed task is blocked by a full pool with the REJECT policy")
+	}
+	close(release)
+
+	if len(rejections) != 1 || rejections[0] != REJECT {
+		t.Errorf("unexpected rejections: %v", rejections)
+	}
+	if rejected := wp.Stats().RejectedTasks; rejected != 1 {
+		t.Errorf("unexpected rejected tasks, got: %d, want: 1", rejected)
+	}
+	waitForCondition(t, func() bool { return wp.keyedTasks.len() == 0 }, "key of the rejected task is not removed")
+}
@@ -0,0 +1,84 @@
+package worker_pool
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/domain"
+)
+
+// TaskHandler executes a task with the context of its execution.
+type TaskHandler func(ctx context.Context, task *gr_worker.Task) error
+
+// Middleware wraps the execution of every task of a pool, e.g. to add tracing or logging.
+type Middleware func(next TaskHandler) TaskHandler
+
+// Hooks are called on the lifecycle events of a pool. They are called synchronously from the goroutine
+// of the event, so they should return quickly. Any of them can be nil. A panic of OnTaskStart or OnTaskDone
+// fails the task with a *gr_worker.PanicError.
+type Hooks struct {
+	// OnWorkerStart is called from the goroutine of a new worker before it reads any task.
+	OnWorkerStart func()
+	// OnWorkerStop is called once a worker is allowed to stop, with the reason of the stop.
+	OnWorkerStop func(status domain.WorkerStatus)
+	// OnTaskQueued is called once a task is added to the task queue.
+	OnTaskQueued func(task *gr_worker.Task)
+	// OnTaskRejected is called for a task refused by the full task queue, with the rejection policy applied to it.
+	OnTaskRejected func(task *gr_worker.Task, policy RejectionPolicy)
+	// OnTaskStart is called by the worker right before executing the task.
+	OnTaskStart func(task *gr_worker.Task)
+	// OnTaskDone is called after every execution of a task with its error and its duration.
+	OnTaskDone func(task *gr_worker.Task, err error, duration time.Duration)
+}
+
+// chainMiddlewares builds the task handler of the pool, the first middleware is the outermost one.
+func chainMiddlewares(middlewares []Middleware) TaskHandler {
+	handler := executeTask
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+func executeTask(ctx context.Context, task *gr_worker.Task) error {
+	return task.ExecuteTaskWithContext(ctx)
+}
+
+// runTaskHandler executes the task through the middlewares and the hooks, a panic of a middleware or a hook
+// is reported as a *gr_worker.PanicError like a panic of the task. A task whose OnTaskStart panics is not executed.
+func (wp *WorkerPoolAdapter) runTaskHandler(ctx context.Context, task *gr_worker.Task) (err error) {
+	startedAt := time.Now()
+	defer func() {
+		duration := time.Since(startedAt)
+		wp.counters.execution.observe(duration)
+		if wp.limiter != nil {
+			wp.limiter.observe(duration, err)
+		}
+		if wp.hooks.OnTaskDone != nil {
+			if hookErr := wp.runTaskDoneHook(task, err, duration); hookErr != nil && err == nil {
+				err = hookErr
+			}
+		}
+	}()
+	defer recoverPanic(&err)
+
+	if wp.hooks.OnTaskStart != nil {
+		wp.hooks.OnTaskStart(task)
+	}
+	return wp.taskHandler(ctx, task)
+}
+
+func (wp *WorkerPoolAdapter) runTaskDoneHook(task *gr_worker.Task, taskErr error, duration time.Duration) (err error) {
+	defer recoverPanic(&err)
+	wp.hooks.OnTaskDone(task, taskErr, duration)
+	return nil
+}
+
+// recoverPanic must be deferred, it replaces the error with a *gr_worker.PanicError of the recovered panic.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &gr_worker.PanicError{Value: r, Stack: debug.Stack()}
+	}
+}
@@ -0,0 +1,156 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/domain"
+	"github.com/vd09/gr_worker/logger"
+)
+
+type contextKey string
+
+func TestWorkerPoolAdapter_WithMiddleware(t *testing.T) {
+	var mutex sync.Mutex
+	var calls []string
+	record := func(call string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls = append(calls, call)
+	}
+	middleware := func(name string) Middleware {
+		return func(next TaskHandler) TaskHandler {
+			return func(ctx context.Context, task *gr_worker.Task) error {
+				record(name + " before")
+				err := next(context.WithValue(ctx, contextKey(name), true), task)
+				record(name + " after")
+				return err
+			}
+		}
+	}
+
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMiddleware(middleware("first")), WithMiddleware(middleware("second")))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	future, _ := wp.Submit(func(ctx context.Context) error {
+		if ctx.Value(contextKey("first")) == nil || ctx.Value(contextKey("second")) == nil {
+			return errors.New("context of the middlewares is not passed to the task")
+		}
+		record("task")
+		return nil
+	})
+	if err := future.Wait(testContext(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"first before", "second before", "task", "second after", "first after"}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(calls) != len(expected) {
+		t.Fatalf("unexpected calls, got: %v, want: %v", calls, expected)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("unexpected calls, got: %v, want: %v", calls, expected)
+			break
+		}
+	}
+}
+
+func TestWorkerPoolAdapter_WithMiddleware_Panic(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithLogger(logger.Discard), WithMiddleware(func(next TaskHandler) TaskHandler {
+		return func(ctx context.Context, task *gr_worker.Task) error {
+			panic("middleware failure")
+		}
+	}))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	future, _ := wp.Submit(func() {})
+	var panicErr *gr_worker.PanicError
+	if err := future.Wait(testContext(t)); !errors.As(err, &panicErr) {
+		t.Errorf("unexpected error, got: %v, want a *gr_worker.PanicError", err)
+	}
+}
+
+func TestWorkerPoolAdapter_WithHooks(t *testing.T) {
+	var workersStarted, workersStopped, tasksQueued, tasksStarted atomic.Int32
+	done := make(chan error, 1)
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithLogger(logger.Discard), WithHooks(Hooks{
+		OnWorkerStart: func() { workersStarted.Add(1) },
+		OnWorkerStop: func(status domain.WorkerStatus) {
+			workersStopped.Add(1)
+		},
+		OnTaskQueued: func(task *gr_worker.Task) { tasksQueued.Add(1) },
+		OnTaskStart:  func(task *gr_worker.Task) { tasksStarted.Add(1) },
+		OnTaskDone: func(task *gr_worker.Task, err error, duration time.Duration) {
+			if duration < 5*time.Millisecond {
+				err = errors.New("unexpected task duration")
+			}
+			done <- err
+		},
+	}))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	wp.AddTask(func() error {
+		time.Sleep(5 * time.Millisecond)
+		return errTemporary
+	})
+	if err := <-done; err != errTemporary {
+		t.Errorf("unexpected error of the done hook, got: %v, want: %v", err, errTemporary)
+	}
+	wp.WaitAndStop()
+
+	if tasksQueued.Load() != 1 || tasksStarted.Load() != 1 {
+		t.Errorf("unexpected task hooks, queued: %d, started: %d", tasksQueued.Load(), tasksStarted.Load())
+	}
+	if workersStarted.Load() != 1 || workersStopped.Load() != 1 {
+		t.Errorf("unexpected worker hooks, started: %d, stopped: %d", workersStarted.Load(), workersStopped.Load())
+	}
+}
+
+func TestWorkerPoolAdapter_WithHooks_Panic(t *testing.T) {
+	tests := []struct {
+		name             string
+		hooks            Hooks
+		expectedExecuted bool
+	}{
+		{"OnTaskStart", Hooks{OnTaskStart: func(task *gr_worker.Task) { panic("start") }}, false},
+		{"OnTaskDone", Hooks{OnTaskDone: func(task *gr_worker.Task, err error, duration time.Duration) { panic("done") }}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithLogger(logger.Discard), WithHooks(test.hooks))
+			if err != nil {
+				t.Fatalf("error creating worker pool: %v", err)
+			}
+			defer wp.Stop()
+
+			var executed atomic.Bool
+			future, _ := wp.Submit(func() { executed.Store(true) })
+			var panicErr *gr_worker.PanicError
+			if err := future.Wait(testContext(t)); !errors.As(err, &panicErr) {
+				t.Errorf("unexpected error, got: %v, want a *gr_worker.PanicError", err)
+			}
+			if executed.Load() != test.expectedExecuted {
+				t.Errorf("unexpected execution of the task, got: %v, want: %v", executed.Load(), test.expectedExecuted)
+			}
+			if panicked := wp.Stats().PanickedTasks; panicked != 1 {
+				t.Errorf("unexpected panicked tasks, got: %d, want: 1", panicked)
+			}
+		})
+	}
+}
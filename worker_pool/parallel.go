@@ -0,0 +1,231 @@
+package worker_pool
+
+import (
+	"context"
+)
+
+// MapOrder tells in which order MapChan emits the results.
+type MapOrder int
+
+const (
+	// ORDERED emits the results in the order of the inputs, a slow input holds back the results behind it.
+	ORDERED MapOrder = iota
+	// UNORDERED emits every result as soon as it is available.
+	UNORDERED
+)
+
+// MapResult is a result emitted by MapChan, with the error of the function or of the submission of its input.
+type MapResult[R any] struct {
+	Value R
+	Err   error
+}
+
+// Map calls the function for every item on the workers of the pool and returns the results in the order
+// of the items. On the first error, the items which are not started yet are skipped and the error is returned.
+func Map[T, R any](ctx context.Context, pool WorkerPool, items []T, fn func(T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	group := pool.NewGroup(ctx, WithCancelOnError())
+	for i := range items {
+		i := i
+		group.Go(func() error {
+			result, err := fn(items[i])
+			results[i] = result
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ForEach calls the function for every item on the workers of the pool, like Map without results.
+func ForEach[T any](ctx context.Context, pool WorkerPool, items []T, fn func(T) error) error {
+	group := pool.NewGroup(ctx, WithCancelOnError())
+	for _, item := range items {
+		item := item
+		group.Go(func() error { return fn(item) })
+	}
+	return group.Wait()
+}
+
+// Reduce maps the items on the workers of the pool like Map, then folds the results in the order of the items
+// on the calling goroutine.
+func Reduce[T, R, A any](ctx context.Context, pool WorkerPool, items []T, fn func(T) (R, error),
+	initial A, reduce func(A, R) A) (A, error) {
+	results, err := Map(ctx, pool, items, fn)
+	if err != nil {
+		return initial, err
+	}
+
+	acc := initial
+	for _, result := range results {
+		acc = reduce(acc, result)
+	}
+	return acc, nil
+}
+
+// MapChan calls the function for every input received from the channel on the workers of the pool and emits
+// the results on the returned channel, which is closed once the input channel is closed and all the results
+// are emitted. At most as many inputs as the concurrency limit of the pool are in flight, so a slow consumer
+// slows down the reading of the inputs. An input which can't be submitted is emitted with the error
+// of the submission and stops the reading of the inputs. Once the context is done, no input is read anymore,
+// the results left are not emitted and the channel is closed.
+func MapChan[T, R any](ctx context.Context, pool WorkerPool, in <-chan T, fn func(T) (R, error), order MapOrder) <-chan MapResult[R] {
+	window := pool.Stats().ConcurrencyLimit
+	if window < 1 {
+		window = 1
+	}
+
+	out := make(chan MapResult[R])
+	if order == UNORDERED {
+		go mapUnordered(ctx, pool, in, fn, window, out)
+	} else {
+		go mapOrdered(ctx, pool, in, fn, window, out)
+	}
+	return out
+}
+
+// mapItem is an input of MapChan in flight, its value is written by the task before its future is finished.
+type mapItem[R any] struct {
+	future *Future
+	value  R
+	err    error
+}
+
+func submitMapItem[T, R any](ctx context.Context, pool WorkerPool, input T, fn func(T) (R, error)) *mapItem[R] {
+	item := &mapItem[R]{}
+	item.future, item.err = pool.SubmitWithContext(ctx, func() error {
+		value, err := fn(input)
+		item.value = value
+		return err
+	})
+	return item
+}
+
+// result returns the result of a finished item.
+func (item *mapItem[R]) result() MapResult[R] {
+	if item.err != nil {
+		return MapResult[R]{Err: item.err}
+	}
+	if err := item.future.err; err != nil {
+		return MapResult[R]{Err: err}
+	}
+	return MapResult[R]{Value: item.value}
+}
+
+func mapOrdered[T, R any](ctx context.Context, pool WorkerPool, in <-chan T, fn func(T) (R, error), window int,
+	out chan<- MapResult[R]) {
+	defer close(out)
+
+	// pending holds the items in flight in the order of the inputs, its capacity bounds them.
+	pending := make(chan *mapItem[R], window)
+	go func() {
+		defer close(pending)
+		for {
+			input, ok := receiveMapInput(ctx, in)
+			if !ok {
+				return
+			}
+			item := submitMapItem(ctx, pool, input, fn)
+			select {
+			case pending <- item:
+			case <-ctx.Done():
+				return
+			}
+			if item.err != nil {
+				return
+			}
+		}
+	}()
+
+	for item := range pending {
+		if item.future != nil {
+			select {
+			case <-item.future.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		if !sendMapResult(ctx, out, item.result()) {
+			return
+		}
+	}
+}
+
+func mapUnordered[T, R any](ctx context.Context, pool WorkerPool, in <-chan T, fn func(T) (R, error), window int,
+	out chan<- MapResult[R]) {
+	defer close(out)
+
+	// slots bounds the items in flight, so done never blocks as it has room for all of them.
+	slots := make(chan struct{}, window)
+	done := make(chan *mapItem[R], window)
+	submitted := 0
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			input, ok := receiveMapInput(ctx, in)
+			if !ok {
+				return
+			}
+			item := submitMapItem(ctx, pool, input, fn)
+			submitted++
+			if item.err != nil || !item.future.setOnFinish(func() { done <- item }) {
+				done <- item
+			}
+			if item.err != nil {
+				return
+			}
+		}
+	}()
+
+	emitted := 0
+	for {
+		select {
+		case item := <-done:
+			if !sendMapResult(ctx, out, item.result()) {
+				return
+			}
+			emitted++
+			<-slots
+		case <-finished:
+			// No input is submitted anymore, the items left in flight are emitted before closing.
+			for ; emitted < submitted; emitted++ {
+				select {
+				case item := <-done:
+					if !sendMapResult(ctx, out, item.result()) {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+			return
+		}
+	}
+}
+
+func receiveMapInput[T any](ctx context.Context, in <-chan T) (T, bool) {
+	select {
+	case input, ok := <-in:
+		return input, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+func sendMapResult[R any](ctx context.Context, out chan<- MapResult[R], result MapResult[R]) bool {
+	select {
+	case out <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
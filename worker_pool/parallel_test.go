@@ -0,0 +1,147 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/logger"
+)
+
+func newParallelPool(t *testing.T) WorkerPool {
+	wp, err := NewWorkerPool(WithMaxWorkers(4), WithMaxTasks(8), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	t.Cleanup(wp.Stop)
+	return wp
+}
+
+func TestMap(t *testing.T) {
+	wp := newParallelPool(t)
+
+	results, err := Map(context.Background(), wp, []int{1, 2, 3, 4, 5}, func(value int) (string, error) {
+		time.Sleep(time.Duration(5-value) * time.Millisecond)
+		return strconv.Itoa(value * value), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"1", "4", "9", "16", "25"}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Fatalf("unexpected results, got: %v, want: %v", results, expected)
+		}
+	}
+
+	_, err = Map(context.Background(), wp, []int{1, 2, 3}, func(value int) (int, error) {
+		if value == 2 {
+			return 0, errTemporary
+		}
+		return value, nil
+	})
+	if err != errTemporary {
+		t.Errorf("unexpected error, got: %v, want: %v", err, errTemporary)
+	}
+}
+
+func TestForEachAndReduce(t *testing.T) {
+	wp := newParallelPool(t)
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	var sum atomic.Int64
+	err := ForEach(context.Background(), wp, items, func(value int) error {
+		sum.Add(int64(value))
+		return nil
+	})
+	if err != nil || sum.Load() != 55 {
+		t.Errorf("unexpected ForEach result, sum: %d, error: %v", sum.Load(), err)
+	}
+
+	joined, err := Reduce(context.Background(), wp, items, func(value int) (int, error) {
+		return value * 2, nil
+	}, "", func(acc string, value int) string {
+		return acc + strconv.Itoa(value) + ","
+	})
+	if expected := "2,4,6,8,10,12,14,16,18,20,"; err != nil || joined != expected {
+		t.Errorf("unexpected Reduce result, got: %q, want: %q, error: %v", joined, expected, err)
+	}
+}
+
+func TestMapChan(t *testing.T) {
+	tests := []struct {
+		name  string
+		order MapOrder
+	}{
+		{"Ordered", ORDERED},
+		{"Unordered", UNORDERED},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wp := newParallelPool(t)
+
+			in := make(chan int)
+			go func() {
+				defer close(in)
+				for i := 0; i < 20; i++ {
+					in <- i
+				}
+			}()
+
+			var values []int
+			for result := range MapChan(testContext(t), wp, in, func(value int) (int, error) {
+				time.Sleep(time.Duration(value%3) * time.Millisecond)
+				if value == 7 {
+					return 0, errTemporary
+				}
+				return value, nil
+			}, test.order) {
+				if result.Err != nil {
+					if !errors.Is(result.Err, errTemporary) {
+						t.Errorf("unexpected error: %v", result.Err)
+					}
+					values = append(values, 7)
+					continue
+				}
+				values = append(values, result.Value)
+			}
+
+			if len(values) != 20 {
+				t.Fatalf("unexpected results: %v", values)
+			}
+			if test.order == UNORDERED {
+				sort.Ints(values)
+			}
+			for i, value := range values {
+				if value != i {
+					t.Fatalf("unexpected results: %v", values)
+				}
+			}
+		})
+	}
+}
+
+func TestMapChan_ContextCancelled(t *testing.T) {
+	wp := newParallelPool(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	out := MapChan(ctx, wp, in, func(value int) (int, error) { return value, nil }, UNORDERED)
+	in <- 1
+	<-out
+	cancel()
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("unexpected result after the context is cancelled")
+		}
+	case <-time.After(time.Second):
+		t.Error("results channel is not closed after the context is cancelled")
+	}
+}
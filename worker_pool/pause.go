@@ -0,0 +1,41 @@
+package worker_pool
+
+// Pause stops handing queued tasks to the workers, the running tasks are not affected and new tasks are still
+// accepted into the queue. Idle workers block without timing out until the pool is resumed.
+// WaitAndStop and Drain wait for the queued tasks, so on a paused pool they only return once it is resumed
+// or their context is done.
+func (wp *WorkerPoolAdapter) Pause() {
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+
+	if wp.paused.Load() {
+		return
+	}
+	wp.paused.Store(true)
+	if wp.pausedSignal != nil {
+		close(wp.pausedSignal)
+		wp.pausedSignal = nil
+	}
+	wp.notifyQueueChangedLocked()
+}
+
+// Resume hands the queued tasks to the workers again.
+func (wp *WorkerPoolAdapter) Resume() {
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+
+	wp.paused.Store(false)
+	wp.notifyQueueChangedLocked()
+}
+
+func (wp *WorkerPoolAdapter) IsPaused() bool {
+	return wp.paused.Load()
+}
+
+// pausedSignalLocked returns a channel which is closed once the pool is paused.
+func (wp *WorkerPoolAdapter) pausedSignalLocked() <-chan struct{} {
+	if wp.pausedSignal == nil {
+		wp.pausedSignal = make(chan struct{})
+	}
+	return wp.pausedSignal
+}
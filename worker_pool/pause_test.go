@@ -0,0 +1,53 @@
+package worker_pool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/worker"
+)
+
+func TestWorkerPoolAdapter_PauseResume(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMinWorkers(0), WithMaxWorkers(1), WithMaxTasks(3),
+		WithWorkerStrategy(worker.IDEAL_WORKER_TIMEOUT), WithIdleTimeout(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	wp.Pause()
+	if !wp.IsPaused() || !wp.Stats().Paused {
+		t.Fatal("pool is not paused")
+	}
+
+	executed := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		if !wp.AddTask(func() { executed <- struct{}{} }) {
+			t.Fatal("task is not accepted by a paused pool")
+		}
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	stats := wp.Stats()
+	if len(executed) != 0 {
+		t.Errorf("tasks are executed by a paused pool: %d", len(executed))
+	}
+	if stats.QueuedTasks != 3 {
+		t.Errorf("unexpected queued tasks, got: %d, want: 3", stats.QueuedTasks)
+	}
+	if stats.ActiveWorkers != 1 {
+		t.Errorf("idle worker timed out while the pool is paused, active workers: %d", stats.ActiveWorkers)
+	}
+
+	wp.Resume()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-executed:
+		case <-time.After(time.Second):
+			t.Fatal("tasks are not executed after resume")
+		}
+	}
+	if wp.IsPaused() {
+		t.Error("pool is still paused")
+	}
+}
@@ -0,0 +1,102 @@
+package worker_pool
+
+import (
+	"fmt"
+	"time"
+)
+
+// RejectionPolicy tells what happens to a task submitted while the task queue is full.
+type RejectionPolicy int
+
+const (
+	// BLOCK waits until there is space in the queue.
+	BLOCK RejectionPolicy = iota
+	// BLOCK_WITH_TIMEOUT waits for space in the queue at most the block timeout, then rejects the task
+	// with ErrQueueFull.
+	BLOCK_WITH_TIMEOUT
+	// REJECT rejects the task with ErrQueueFull.
+	REJECT
+	// DROP_OLDEST removes the oldest queued task to make space for the task, the removed task finishes
+	// with ErrQueueFull.
+	DROP_OLDEST
+	// DROP_NEWEST accepts the task but drops it right away, its future finishes with ErrQueueFull.
+	DROP_NEWEST
+	// CALLER_RUNS executes the task on the goroutine which submits it.
+	CALLER_RUNS
+)
+
+const DefaultRejectionPolicy = BLOCK
+
+func (p RejectionPolicy) String() string {
+	switch p {
+	case BLOCK:
+		return "block"
+	case BLOCK_WITH_TIMEOUT:
+		return "block_with_timeout"
+	case REJECT:
+		return "reject"
+	case DROP_OLDEST:
+		return "drop_oldest"
+	case DROP_NEWEST:
+		return "drop_newest"
+	case CALLER_RUNS:
+		return "caller_runs"
+	}
+	return fmt.Sprintf("RejectionPolicy(%d)", int(p))
+}
+
+func (p RejectionPolicy) isValid() bool {
+	return p >= BLOCK && p <= CALLER_RUNS
+}
+
+// submitWithRejectionPolicy adds a submitted task to the queue, applying the rejection policy of the pool
+// once the queue is full.
+func (wp *WorkerPoolAdapter) submitWithRejectionPolicy(future *Future) error {
+	err := wp.writeTask(future, wp.rejectionPolicy)
+	if err != ErrQueueFull {
+		return err
+	}
+
+	switch wp.rejectionPolicy {
+	case DROP_NEWEST:
+		wp.rejectTask(future, DROP_NEWEST)
+		return nil
+	case CALLER_RUNS:
+		wp.runOnCaller(future)
+		return nil
+	}
+	wp.reportRejection(future, wp.rejectionPolicy)
+	return err
+}
+
+// rejectTask finishes a task accepted by the pool which is dropped by the rejection policy.
+func (wp *WorkerPoolAdapter) rejectTask(future *Future, policy RejectionPolicy) {
+	if !future.drop(ErrQueueFull) {
+		return
+	}
+	wp.counters.rejected.Add(1)
+	wp.reportRejection(future, policy)
+	wp.sendDeadLetter(future, TASK_DROPPED, ErrQueueFull)
+}
+
+// runOnCaller executes the task on the current goroutine, like a task executed by a worker.
+func (wp *WorkerPoolAdapter) runOnCaller(future *Future) {
+	wp.counters.rejected.Add(1)
+	wp.reportRejection(future, CALLER_RUNS)
+
+	wp.taskTracker.add(future)
+	future.enqueuedAt = time.Now()
+	// A retry of the task is executed by the pool, the caller is not kept waiting for the backoff.
+	_, err := wp.addConcurrencyDetailsToNewTask(future)
+	wp.logTaskFailure(future.Task(), err)
+}
+
+func (wp *WorkerPoolAdapter) reportRejection(future *Future, policy RejectionPolicy) {
+	wp.queueMutex.Lock()
+	wp.queue.rejected(future)
+	wp.queueMutex.Unlock()
+
+	if wp.hooks.OnTaskRejected != nil {
+		wp.hooks.OnTaskRejected(future.Task(), policy)
+	}
+}
@@ -0,0 +1,163 @@
+package worker_pool
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/logger"
+)
+
+// newFullPool creates a pool with a single worker blocked on a task and a full queue of one task.
+func newFullPool(t *testing.T, policy RejectionPolicy, rejected *[]RejectionPolicy) (*WorkerPoolAdapter, *Future, func()) {
+	var mutex sync.Mutex
+	hooks := Hooks{OnTaskRejected: func(task *gr_worker.Task, policy RejectionPolicy) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		*rejected = append(*rejected, policy)
+	}}
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(1), WithLogger(logger.Discard),
+		WithRejectionPolicy(policy), WithBlockTimeout(10*time.Millisecond), WithHooks(hooks))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	wp.AddTask(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	queued, err := wp.Submit(func() {})
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+	return wp, queued, func() { close(release) }
+}
+
+func TestWorkerPoolAdapter_RejectionPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        RejectionPolicy
+		expectedError error
+		// expectedResult is the error of the submitted task, expectedQueued the one of the task queued before.
+		expectedResult error
+		expectedQueued error
+	}{
+		{"BlockWithTimeout", BLOCK_WITH_TIMEOUT, ErrQueueFull, nil, nil},
+		{"Reject", REJECT, ErrQueueFull, nil, nil},
+		{"DropOldest", DROP_OLDEST, nil, nil, ErrQueueFull},
+		{"DropNewest", DROP_NEWEST, nil, ErrQueueFull, nil},
+		{"CallerRuns", CALLER_RUNS, nil, nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var rejected []RejectionPolicy
+			wp, queued, release := newFullPool(t, test.policy, &rejected)
+			defer wp.Stop()
+
+			var caller bool
+			submitting := make(chan struct{})
+			future, err := wp.Submit(func() {
+				select {
+				case <-submitting:
+				default:
+					caller = true
+				}
+			})
+			close(submitting)
+			if err != test.expectedError {
+				t.Fatalf("unexpected submit error, got: %v, want: %v", err, test.expectedError)
+			}
+			release()
+
+			if future != nil {
+				if err := future.Wait(testContext(t)); err != test.expectedResult {
+					t.Errorf("unexpected task error, got: %v, want: %v", err, test.expectedResult)
+				}
+			}
+			if err := queued.Wait(testContext(t)); err != test.expectedQueued {
+				t.Errorf("unexpected queued task error, got: %v, want: %v", err, test.expectedQueued)
+			}
+			if caller != (test.policy == CALLER_RUNS) {
+				t.Errorf("unexpected task execution on the caller: %v", caller)
+			}
+			if len(rejected) != 1 || rejected[0] != test.policy {
+				t.Errorf("unexpected rejections reported to the hook: %v", rejected)
+			}
+			if stats := wp.Stats(); stats.RejectedTasks != 1 {
+				t.Errorf("unexpected rejected tasks, got: %d, want: 1", stats.RejectedTasks)
+			}
+		})
+	}
+}
+
+func TestWorkerPoolAdapter_RejectionPolicyBlock(t *testing.T) {
+	var rejected []RejectionPolicy
+	wp, _, release := newFullPool(t, BLOCK, &rejected)
+	defer wp.Stop()
+
+	time.AfterFunc(10*time.Millisecond, release)
+	future, err := wp.Submit(func() {})
+	if err != nil {
+		t.Fatalf("unexpected submit error: %v", err)
+	}
+	if err := future.Wait(testContext(t)); err != nil {
+		t.Errorf("unexpected task error: %v", err)
+	}
+	if len(rejected) != 0 {
+		t.Errorf("unexpected rejections reported to the hook: %v", rejected)
+	}
+}
+
+func TestWorkerPoolAdapter_ValidateRejectionPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       []Option
+		expectedError error
+	}{
+		{"UnknownPolicy", []Option{WithRejectionPolicy(RejectionPolicy(42))}, ErrRejection},
+		{"MissingBlockTimeout", []Option{WithRejectionPolicy(BLOCK_WITH_TIMEOUT)}, ErrBlockTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewWorkerPool(test.options...); err != test.expectedError {
+				t.Errorf("unexpected error, got: %v, want: %v", err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestWorkerPoolAdapter_RejectionPolicyCallerRunsLogsFailure(t *testing.T) {
+	var logs []string
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(1), WithRejectionPolicy(CALLER_RUNS),
+		WithLogger(logger.Func(func(format string, v ...interface{}) {
+			logs = append(logs, fmt.Sprintf(format, v...))
+		})))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	wp.AddTask(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	wp.AddTask(func() {})
+
+	// The queue is full, the task is executed and logged on this goroutine.
+	wp.AddTask(failingTask)
+	if len(logs) != 1 || !strings.HasPrefix(logs[0], "[ERROR] Function github.com/vd09/gr_worker/worker_pool.failingTask ") {
+		t.Errorf("failure of the task run by the caller is not logged: %q", logs)
+	}
+}
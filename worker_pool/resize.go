@@ -0,0 +1,124 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNegativeMinWorkers = errors.New("min workers can't be less than zero")
+
+// poolWorker is the handle of a running worker, its context is cancelled to retire the worker.
+type poolWorker struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	retiring bool
+}
+
+func (wp *WorkerPoolAdapter) addPoolWorkerLocked() *poolWorker {
+	ctx, cancel := context.WithCancel(wp.ctx)
+	poolWorker := &poolWorker{ctx: ctx, cancel: cancel}
+	wp.poolWorkers[poolWorker] = struct{}{}
+	return poolWorker
+}
+
+// retireSurplusWorkersLocked cancels the context of the workers above the max workers. A worker stops once it
+// finishes its current task, the tasks themselves are not cancelled.
+func (wp *WorkerPoolAdapter) retireSurplusWorkersLocked() {
+	surplus := wp.activeWorkerCount.Load() - wp.maxWorkers
+	for poolWorker := range wp.poolWorkers {
+		if poolWorker.retiring {
+			surplus--
+		}
+	}
+	for poolWorker := range wp.poolWorkers {
+		if surplus <= 0 {
+			return
+		}
+		if !poolWorker.retiring {
+			poolWorker.retiring = true
+			poolWorker.cancel()
+			surplus--
+		}
+	}
+}
+
+// SetMaxWorkers changes the max workers of a running pool. Surplus workers are retired once they finish their
+// current task, new workers are started for the queued tasks if the limit is raised.
+func (wp *WorkerPoolAdapter) SetMaxWorkers(maxWorkers int32) error {
+	if maxWorkers <= 0 {
+		return ErrMaxWorkers
+	}
+
+	wp.mutex.Lock()
+	if wp.IsWorkerPoolStopped() {
+		wp.mutex.Unlock()
+		return ErrWorkerPoolStopped
+	}
+	if wp.minWorkers > maxWorkers {
+		wp.mutex.Unlock()
+		return ErrMinWorkers
+	}
+	wp.maxWorkers = maxWorkers
+	wp.workerLimit.Store(maxWorkers)
+	if wp.limiter != nil {
+		wp.limiter.setBounds(wp.minWorkers, wp.maxWorkers)
+	}
+	wp.retireSurplusWorkersLocked()
+	wp.mutex.Unlock()
+
+	for queued := wp.queueLength(); queued > 0; queued-- {
+		if !wp.startNewWorkerIfRequired() {
+			break
+		}
+	}
+	return nil
+}
+
+// SetMinWorkers changes the min workers of a running pool, missing workers are started right away.
+// Lowering it allows the idle workers of the IDEAL_WORKER_TIMEOUT strategy to time out.
+func (wp *WorkerPoolAdapter) SetMinWorkers(minWorkers int32) error {
+	if minWorkers < 0 {
+		return ErrNegativeMinWorkers
+	}
+
+	wp.mutex.Lock()
+	if wp.IsWorkerPoolStopped() {
+		wp.mutex.Unlock()
+		return ErrWorkerPoolStopped
+	}
+	if minWorkers > wp.maxWorkers {
+		wp.mutex.Unlock()
+		return ErrMinWorkers
+	}
+	wp.minWorkers = minWorkers
+	if wp.limiter != nil {
+		wp.limiter.setBounds(wp.minWorkers, wp.maxWorkers)
+	}
+	missing := minWorkers - wp.activeWorkerCount.Load()
+	wp.mutex.Unlock()
+
+	for ; missing > 0; missing-- {
+		if !wp.startNewWorkerIfRequired() {
+			break
+		}
+	}
+	return nil
+}
+
+// SetMaxTasks changes the capacity of the task queue of a running pool. No queued task is lost when the capacity
+// is lowered below the number of queued tasks, new tasks are only accepted once the queue is below the capacity.
+func (wp *WorkerPoolAdapter) SetMaxTasks(maxTasks int32) error {
+	if maxTasks <= 0 {
+		return ErrMaxTasks
+	}
+	if wp.IsWorkerPoolStopped() {
+		return ErrWorkerPoolStopped
+	}
+
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+
+	wp.maxTasks = maxTasks
+	wp.notifyQueueChangedLocked()
+	return nil
+}
@@ -0,0 +1,112 @@
+package worker_pool
+
+import (
+	"testing"
+	"time"
+)
+
+func waitForCondition(t *testing.T, condition func() bool, message string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWorkerPoolAdapter_SetMaxWorkers(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMinWorkers(0), WithMaxWorkers(4), WithMaxTasks(10))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	release := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wp.AddTask(func() { <-release })
+	}
+	waitForCondition(t, func() bool { return wp.Stats().BusyWorkers == 4 }, "tasks are not started")
+
+	if err := wp.SetMaxWorkers(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if active := wp.Stats().ActiveWorkers; active != 4 {
+		t.Errorf("busy workers are retired before finishing their task, active workers: %d", active)
+	}
+	close(release)
+	waitForCondition(t, func() bool { return wp.Stats().ActiveWorkers == 2 }, "surplus workers are not retired")
+
+	block := make(chan struct{})
+	defer close(block)
+	for i := 0; i < 4; i++ {
+		wp.AddTask(func() { <-block })
+	}
+	waitForCondition(t, func() bool { return wp.Stats().BusyWorkers == 2 }, "tasks are not started")
+	if err := wp.SetMaxWorkers(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForCondition(t, func() bool { return wp.Stats().BusyWorkers == 3 }, "worker is not started for the queued tasks")
+}
+
+func TestWorkerPoolAdapter_SetMinWorkers(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMinWorkers(0), WithMaxWorkers(4))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	if err := wp.SetMinWorkers(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if active := wp.Stats().ActiveWorkers; active != 3 {
+		t.Errorf("unexpected active workers, got: %d, want: 3", active)
+	}
+
+	if err := wp.SetMinWorkers(5); err != ErrMinWorkers {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrMinWorkers)
+	}
+	if err := wp.SetMinWorkers(-1); err != ErrNegativeMinWorkers {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrNegativeMinWorkers)
+	}
+	if err := wp.SetMaxWorkers(2); err != ErrMinWorkers {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrMinWorkers)
+	}
+}
+
+func TestWorkerPoolAdapter_SetMaxTasks(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(3))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	wp.AddTask(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	executed := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		i := i
+		wp.AddTask(func() { executed <- i })
+	}
+
+	if err := wp.SetMaxTasks(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wp.AddTaskIfSpaceAvailable(func() {}) {
+		t.Error("task is added above the new capacity")
+	}
+	if err := wp.SetMaxTasks(0); err != ErrMaxTasks {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrMaxTasks)
+	}
+
+	close(release)
+	wp.WaitAndStop()
+	if len(executed) != 3 {
+		t.Errorf("queued tasks are lost, executed: %d, want: 3", len(executed))
+	}
+}
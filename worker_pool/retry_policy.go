@@ -0,0 +1,196 @@
+package worker_pool
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/vd09/gr_worker"
+)
+
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBackoff     = 100 * time.Millisecond
+	DefaultRetryMultiplier  = 2.0
+)
+
+var (
+	ErrRetryAttempts   = errors.New("retry max attempts can't be less than zero")
+	ErrRetryBackoff    = errors.New("retry backoff can't be less than zero")
+	ErrRetryMultiplier = errors.New("retry multiplier can't be less than one")
+)
+
+// RetryJitter decides how the backoff delay between two attempts is randomized.
+type RetryJitter int
+
+const (
+	// NO_JITTER waits exactly the exponential backoff.
+	NO_JITTER RetryJitter = iota
+	// FULL_JITTER waits a random delay between zero and the exponential backoff.
+	FULL_JITTER
+	// DECORRELATED_JITTER waits a random delay between the initial backoff and three times the previous delay.
+	DECORRELATED_JITTER
+)
+
+// RetryExhaustedFunc receives a task which failed on its last allowed attempt.
+type RetryExhaustedFunc func(task *gr_worker.Task, attempts int, err error)
+
+// RetryPolicy retries a task which returned an error. The task is not retried by the worker which ran it:
+// it is re-enqueued into the pool once the backoff delay has passed, so no worker is held while waiting.
+// Zero values fall back to the defaults, a policy with MaxAttempts of one never retries.
+type RetryPolicy struct {
+	// MaxAttempts is the number of executions of the task, including the first one.
+	// It is DefaultRetryMaxAttempts when zero.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, it is multiplied by Multiplier for every next retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, there is no cap if it is zero.
+	MaxBackoff time.Duration
+	Multiplier float64
+	Jitter     RetryJitter
+
+	// RetryableErrors limits the retries to the errors matching one of them with errors.Is.
+	RetryableErrors []error
+	// Retryable limits the retries to the errors it accepts. If both RetryableErrors and Retryable are set,
+	// an error accepted by either of them is retried. All errors are retried if none of them is set.
+	Retryable func(err error) bool
+
+	// OnExhausted is called when the last allowed attempt of the task fails with a retryable error.
+	OnExhausted RetryExhaustedFunc
+}
+
+func (rp *RetryPolicy) validate() error {
+	if rp.MaxAttempts < 0 {
+		return ErrRetryAttempts
+	}
+	if rp.InitialBackoff < 0 || rp.MaxBackoff < 0 {
+		return ErrRetryBackoff
+	}
+	if rp.Multiplier != 0 && rp.Multiplier < 1 {
+		return ErrRetryMultiplier
+	}
+	return nil
+}
+
+func (rp *RetryPolicy) maxAttempts() int {
+	if rp.MaxAttempts == 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return rp.MaxAttempts
+}
+
+func (rp *RetryPolicy) isRetryable(err error) bool {
+	if len(rp.RetryableErrors) == 0 && rp.Retryable == nil {
+		return true
+	}
+	for _, target := range rp.RetryableErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return rp.Retryable != nil && rp.Retryable(err)
+}
+
+// backoff returns the delay before the next attempt once the given number of attempts has failed.
+func (rp *RetryPolicy) backoff(attempts int, previous time.Duration) time.Duration {
+	initial := rp.InitialBackoff
+	if initial == 0 {
+		initial = DefaultRetryBackoff
+	}
+
+	var delay time.Duration
+	switch rp.Jitter {
+	case DECORRELATED_JITTER:
+		if previous < initial {
+			previous = initial
+		}
+		delay = initial + randomDuration(3*previous-initial)
+	default:
+		multiplier := rp.Multiplier
+		if multiplier == 0 {
+			multiplier = DefaultRetryMultiplier
+		}
+		exponential := float64(initial)
+		for i := 1; i < attempts && (rp.MaxBackoff == 0 || exponential < float64(rp.MaxBackoff)); i++ {
+			exponential *= multiplier
+		}
+		delay = time.Duration(exponential)
+	}
+
+	if rp.MaxBackoff > 0 && delay > rp.MaxBackoff {
+		delay = rp.MaxBackoff
+	}
+	if rp.Jitter == FULL_JITTER {
+		delay = randomDuration(delay)
+	}
+	return delay
+}
+
+// randomDuration returns a random duration in [0, d].
+func randomDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+type retryResult int
+
+const (
+	retryNotAllowed retryResult = iota
+	retryScheduled
+	retryExhausted
+	retryDropped
+)
+
+// finishRetries finishes the futures of the tasks waiting for a retry with the error of their last attempt,
+// as they are not retried anymore once the pool is stopping. The other futures are returned.
+func (wp *WorkerPoolAdapter) finishRetries(futures []*Future) []*Future {
+	remaining := futures[:0]
+	for _, future := range futures {
+		if future.attempts.Load() == 0 {
+			remaining = append(remaining, future)
+			continue
+		}
+		if future.state.CompareAndSwap(int32(futurePending), int32(futureRunning)) {
+			wp.sendDeadLetter(future, TASK_DROPPED, future.lastErr)
+			wp.counters.taskFinished(future.lastErr)
+			future.finish(future.Task().Results(), future.lastErr)
+		}
+	}
+	return remaining
+}
+
+// retryTask re-enqueues a failed task after its backoff delay, unless the task must finish with the error.
+func (wp *WorkerPoolAdapter) retryTask(future *Future, err error) retryResult {
+	policy := future.retryPolicy
+	if policy == nil {
+		policy = wp.retryPolicy
+	}
+	if policy == nil || policy.maxAttempts() < 2 || !policy.isRetryable(err) || future.cancelRequested.Load() || wp.ctx.Err() != nil {
+		return retryNotAllowed
+	}
+	if parentCtx := future.Task().Context(); parentCtx != nil && parentCtx.Err() != nil {
+		return retryNotAllowed
+	}
+
+	attempts := int(future.attempts.Load())
+	if attempts >= policy.maxAttempts() {
+		if policy.OnExhausted != nil {
+			policy.OnExhausted(future.Task(), attempts, err)
+		}
+		return retryExhausted
+	}
+
+	future.lastBackoff = policy.backoff(attempts, future.lastBackoff)
+	future.lastErr = err
+	if !future.retry() {
+		return retryNotAllowed
+	}
+	if !wp.scheduler.schedule(future, time.Now().Add(future.lastBackoff)) {
+		// The pool is stopping, the task finishes with the error of its last attempt.
+		future.state.CompareAndSwap(int32(futurePending), int32(futureRunning))
+		return retryDropped
+	}
+	return retryScheduled
+}
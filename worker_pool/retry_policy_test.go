@@ -0,0 +1,199 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/logger"
+)
+
+var errTemporary = errors.New("temporary failure")
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestWorkerPoolAdapter_SubmitWithRetry(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	var calls atomic.Int32
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond}
+	future, err := wp.SubmitWithRetry(policy, func() (int, error) {
+		if calls.Add(1) < 3 {
+			return 0, errTemporary
+		}
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+
+	results, err := future.Result()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].(int) != 42 {
+		t.Errorf("unexpected result, got: %v, want: 42", results[0])
+	}
+	if future.Attempts() != 3 {
+		t.Errorf("unexpected attempts, got: %d, want: 3", future.Attempts())
+	}
+}
+
+func TestWorkerPoolAdapter_RetryPolicy_Exhausted(t *testing.T) {
+	exhausted := make(chan int, 1)
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithLogger(logger.Discard), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		OnExhausted: func(task *gr_worker.Task, attempts int, err error) {
+			exhausted <- attempts
+		},
+	}))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	future, _ := wp.Submit(func() error { return errTemporary })
+	if err := future.Wait(testContext(t)); !errors.Is(err, errTemporary) {
+		t.Errorf("unexpected error, got: %v, want: %v", err, errTemporary)
+	}
+	if attempts := <-exhausted; attempts != 3 {
+		t.Errorf("unexpected attempts, got: %d, want: 3", attempts)
+	}
+}
+
+func TestWorkerPoolAdapter_RetryPolicy_Retryable(t *testing.T) {
+	tests := []struct {
+		name             string
+		policy           RetryPolicy
+		err              error
+		expectedAttempts int
+	}{
+		{"AllErrors", RetryPolicy{MaxAttempts: 2}, errTemporary, 2},
+		{"RetryableErrors", RetryPolicy{MaxAttempts: 2, RetryableErrors: []error{errTemporary}}, ErrQueueFull, 1},
+		{"WrappedRetryableError", RetryPolicy{MaxAttempts: 2, RetryableErrors: []error{errTemporary}}, &ShutdownError{Err: errTemporary}, 2},
+		{"Predicate", RetryPolicy{MaxAttempts: 2, Retryable: func(err error) bool { return err != errTemporary }}, errTemporary, 1},
+		{"DefaultMaxAttempts", RetryPolicy{}, errTemporary, DefaultRetryMaxAttempts},
+		{"SingleAttempt", RetryPolicy{MaxAttempts: 1}, errTemporary, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wp, err := NewWorkerPool(WithMaxWorkers(1), WithLogger(logger.Discard))
+			if err != nil {
+				t.Fatalf("error creating worker pool: %v", err)
+			}
+			defer wp.Stop()
+
+			test.policy.InitialBackoff = time.Millisecond
+			future, _ := wp.SubmitWithRetry(test.policy, func() error { return test.err })
+			if err := future.Wait(testContext(t)); err != test.err {
+				t.Errorf("unexpected error, got: %v, want: %v", err, test.err)
+			}
+			if future.Attempts() != test.expectedAttempts {
+				t.Errorf("unexpected attempts, got: %d, want: %d", future.Attempts(), test.expectedAttempts)
+			}
+		})
+	}
+}
+
+func TestWorkerPoolAdapter_RetryPolicy_ReleasesWorker(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(2), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	retried, _ := wp.SubmitWithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour}, func() error { return errTemporary })
+	other, _ := wp.Submit(func() {})
+
+	if err := other.Wait(testContext(t)); err != nil {
+		t.Errorf("task is blocked by a task waiting for its retry: %v", err)
+	}
+	if !retried.Cancel() {
+		t.Error("task waiting for its retry is not cancelled")
+	}
+	if _, err := retried.Result(); err != ErrTaskCancelled {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrTaskCancelled)
+	}
+}
+
+func TestWorkerPoolAdapter_RetryPolicy_Drain(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	var calls atomic.Int32
+	retried, _ := wp.SubmitWithRetry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}, func() error {
+		calls.Add(1)
+		return errTemporary
+	})
+	waitForCondition(t, func() bool { return wp.Stats().RetriedTasks == 1 }, "task is not waiting for its retry")
+
+	notExecuted, err := wp.Drain(testContext(t))
+	if err != nil || len(notExecuted) != 0 {
+		t.Errorf("unexpected drain result: %v, error: %v", notExecuted, err)
+	}
+	if err := retried.Wait(testContext(t)); err != errTemporary {
+		t.Errorf("unexpected error, got: %v, want: %v", err, errTemporary)
+	}
+	if calls.Load() != 1 || retried.Attempts() != 1 {
+		t.Errorf("unexpected attempts, calls: %d, attempts: %d", calls.Load(), retried.Attempts())
+	}
+	if failed := wp.Stats().FailedTasks; failed != 1 {
+		t.Errorf("unexpected failed tasks, got: %d, want: 1", failed)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	for i, expected := range []time.Duration{10, 20, 40, 50, 50} {
+		if delay := policy.backoff(i+1, 0); delay != expected*time.Millisecond {
+			t.Errorf("unexpected backoff after %d attempts, got: %v, want: %v", i+1, delay, expected*time.Millisecond)
+		}
+	}
+
+	policy.Jitter = FULL_JITTER
+	for i := 0; i < 100; i++ {
+		if delay := policy.backoff(3, 0); delay < 0 || delay > 40*time.Millisecond {
+			t.Errorf("full jitter backoff %v is out of range", delay)
+		}
+	}
+
+	policy.Jitter = DECORRELATED_JITTER
+	for i := 0; i < 100; i++ {
+		if delay := policy.backoff(3, 15*time.Millisecond); delay < 10*time.Millisecond || delay > 45*time.Millisecond {
+			t.Errorf("decorrelated jitter backoff %v is out of range", delay)
+		}
+	}
+}
+
+func TestRetryPolicy_Validation(t *testing.T) {
+	tests := []struct {
+		policy   RetryPolicy
+		expected error
+	}{
+		{RetryPolicy{MaxAttempts: -1}, ErrRetryAttempts},
+		{RetryPolicy{InitialBackoff: -time.Second}, ErrRetryBackoff},
+		{RetryPolicy{Multiplier: 0.5}, ErrRetryMultiplier},
+		{RetryPolicy{MaxAttempts: 3, Multiplier: 1.5}, nil},
+	}
+
+	for _, test := range tests {
+		if _, err := NewWorkerPool(WithRetryPolicy(test.policy)); err != test.expected {
+			t.Errorf("unexpected error for %+v, got: %v, want: %v", test.policy, err, test.expected)
+		}
+	}
+}
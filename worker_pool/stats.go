@@ -0,0 +1,119 @@
+package worker_pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/vd09/gr_worker"
+)
+
+// Stats is a snapshot of the workers and task counters of a pool.
+type Stats struct {
+	ActiveWorkers int
+	IdleWorkers   int
+	BusyWorkers   int
+	// ConcurrencyLimit is the current limit of the adaptive concurrency limiter, or the max workers without it.
+	ConcurrencyLimit int
+
+	Paused bool
+
+	// QueuedTasks includes the tasks waiting behind their key.
+	QueuedTasks    int
+	ScheduledTasks int
+
+	// SubmittedTasks counts the tasks accepted by the pool, including the scheduled ones. Retries are not counted.
+	SubmittedTasks uint64
+	CompletedTasks uint64
+	// FailedTasks counts the tasks which finished with an error other than a panic, after their last attempt.
+	FailedTasks   uint64
+	PanickedTasks uint64
+	// RejectedTasks counts the submissions which failed and the tasks dropped or run by their caller
+	// by the rejection policy.
+	RejectedTasks uint64
+	RetriedTasks  uint64
+	DroppedTasks  uint64
+
+	// QueueWaitTime is the cumulative time spent by the tasks in the queue before their execution.
+	QueueWaitTime time.Duration
+	// ExecutionTime is the cumulative execution time of the tasks, including the failed attempts.
+	ExecutionTime time.Duration
+}
+
+// poolCounters holds the cumulative counters of a pool, they are only updated with atomic operations.
+type poolCounters struct {
+	submitted atomic.Uint64
+	completed atomic.Uint64
+	failed    atomic.Uint64
+	panicked  atomic.Uint64
+	rejected  atomic.Uint64
+	retried   atomic.Uint64
+	dropped   atomic.Uint64
+
+	queueWait latencyHistogram
+	execution latencyHistogram
+}
+
+// Stats returns a snapshot of the pool. Every value is read atomically without taking any lock of the pool,
+// but the snapshot as a whole is not consistent.
+func (wp *WorkerPoolAdapter) Stats() Stats {
+	active := int(wp.activeWorkerCount.Load())
+	idle := int(wp.idleWorkerCount.Load())
+	busy := active - idle
+	if busy < 0 {
+		busy = 0
+	}
+
+	return Stats{
+		ActiveWorkers:    active,
+		IdleWorkers:      idle,
+		BusyWorkers:      busy,
+		ConcurrencyLimit: int(wp.concurrencyLimit()),
+		Paused:           wp.IsPaused(),
+		QueuedTasks:      wp.queueLength() + int(wp.keyedWaiting.Load()),
+		ScheduledTasks:   wp.scheduler.len(),
+		SubmittedTasks:   wp.counters.submitted.Load(),
+		CompletedTasks:   wp.counters.completed.Load(),
+		FailedTasks:      wp.counters.failed.Load(),
+		PanickedTasks:    wp.counters.panicked.Load(),
+		RejectedTasks:    wp.counters.rejected.Load(),
+		RetriedTasks:     wp.counters.retried.Load(),
+		DroppedTasks:     wp.counters.dropped.Load(),
+		QueueWaitTime:    time.Duration(wp.counters.queueWait.sum.Load()),
+		ExecutionTime:    time.Duration(wp.counters.execution.sum.Load()),
+	}
+}
+
+// QueueWaitHistogram returns the distribution of the time spent by the tasks in the queue before their execution.
+func (wp *WorkerPoolAdapter) QueueWaitHistogram() Histogram {
+	return wp.counters.queueWait.snapshot()
+}
+
+// ExecutionHistogram returns the distribution of the execution time of the tasks, including the failed attempts.
+func (wp *WorkerPoolAdapter) ExecutionHistogram() Histogram {
+	return wp.counters.execution.snapshot()
+}
+
+func (pc *poolCounters) taskSubmitted(err error) {
+	if err != nil {
+		pc.rejected.Add(1)
+		return
+	}
+	pc.submitted.Add(1)
+}
+
+func (pc *poolCounters) taskFinished(err error) {
+	switch {
+	case err == nil:
+		pc.completed.Add(1)
+	case isPanic(err):
+		pc.panicked.Add(1)
+	default:
+		pc.failed.Add(1)
+	}
+}
+
+func isPanic(err error) bool {
+	var panicErr *gr_worker.PanicError
+	return errors.As(err, &panicErr)
+}
@@ -0,0 +1,85 @@
+package worker_pool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/logger"
+)
+
+func TestWorkerPoolAdapter_Stats(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(1), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	completed, _ := wp.Submit(func() { time.Sleep(5 * time.Millisecond) })
+	_ = completed.Wait(testContext(t))
+	failed, _ := wp.Submit(func() error { return errTemporary })
+	_ = failed.Wait(testContext(t))
+	panicked, _ := wp.Submit(func() { panic("boom") })
+	_ = panicked.Wait(testContext(t))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	wp.AddTask(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	wp.AddTask(func() {})
+	if wp.AddTaskIfSpaceAvailable(func() {}) {
+		t.Fatal("task is added to a full queue")
+	}
+
+	stats := wp.Stats()
+	close(release)
+
+	expected := Stats{
+		ActiveWorkers:    1,
+		BusyWorkers:      1,
+		ConcurrencyLimit: 1,
+		QueuedTasks:      1,
+		SubmittedTasks:   5,
+		CompletedTasks:   1,
+		FailedTasks:      1,
+		PanickedTasks:    1,
+		RejectedTasks:    1,
+	}
+	if stats.ExecutionTime < 5*time.Millisecond {
+		t.Errorf("unexpected execution time: %v", stats.ExecutionTime)
+	}
+	if stats.QueueWaitTime <= 0 {
+		t.Errorf("unexpected queue wait time: %v", stats.QueueWaitTime)
+	}
+	stats.ExecutionTime, stats.QueueWaitTime = 0, 0
+	if stats != expected {
+		t.Errorf("unexpected stats\ngot:  %+v\nwant: %+v", stats, expected)
+	}
+}
+
+func TestWorkerPoolAdapter_StatsWithoutLocks(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(2), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	wp.mutex.Lock()
+	wp.queueMutex.Lock()
+	wp.scheduler.mutex.Lock()
+	stats := make(chan Stats, 1)
+	go func() { stats <- wp.Stats() }()
+	select {
+	case s := <-stats:
+		if s.ConcurrencyLimit != 2 {
+			t.Errorf("unexpected concurrency limit, got: %d, want: 2", s.ConcurrencyLimit)
+		}
+	case <-time.After(time.Second):
+		t.Error("stats are blocked by the locks of the pool")
+	}
+	wp.scheduler.mutex.Unlock()
+	wp.queueMutex.Unlock()
+	wp.mutex.Unlock()
+}
@@ -0,0 +1,155 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolAdapter_AddTaskWithContext_StopCancelsTask(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	started := make(chan struct{})
+	future, err := wp.SubmitWithContext(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+
+	<-started
+	wp.Stop()
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := future.Wait(waitCtx); err != context.Canceled {
+		t.Errorf("unexpected error, got: %v, want: %v", err, context.Canceled)
+	}
+}
+
+func TestWorkerPoolAdapter_AddTaskWithContext_ExpiredInQueue(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(2))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	release := make(chan struct{})
+	wp.AddTask(func() { <-release })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	executed := false
+	future, err := wp.SubmitWithContext(ctx, func() { executed = true })
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+	cancel()
+	close(release)
+
+	_, err = future.Result()
+	if !errors.Is(err, ErrTaskContextExpired) || !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrTaskContextExpired)
+	}
+	if executed {
+		t.Error("task with expired context is executed")
+	}
+}
+
+func TestWorkerPoolAdapter_WithTaskTimeout(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithTaskTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	future, err := wp.Submit(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := future.Wait(waitCtx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWorkerPoolAdapter_SubmitWithContext_Deadline(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithTaskTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	future, err := wp.SubmitWithContext(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), time.Second)
+	defer cancelWait()
+	if err := future.Wait(waitCtx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWorkerPoolAdapter_Submit_NilContextParam(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	future, err := wp.Submit(func(ctx context.Context) error {
+		if ctx == nil {
+			return errors.New("context is not injected")
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+	if _, err := future.Result(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWorkerPoolAdapter_WithTaskTimeout_ExplicitContext(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithTaskTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	future, err := wp.Submit(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}, context.Background())
+	if err != nil {
+		t.Fatalf("error submitting task: %v", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if err := future.Wait(waitCtx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
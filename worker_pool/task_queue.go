@@ -0,0 +1,309 @@
+package worker_pool
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/vd09/gr_worker"
+)
+
+// taskQueue orders the queued tasks of a pool, it is guarded by the queue mutex of the pool.
+// The pool uses a fairQueue, which holds a priorityQueue per tenant.
+type taskQueue interface {
+	push(future *Future)
+	pop() *Future
+	// popOldest removes the task which was queued first, regardless of its priority.
+	popOldest() *Future
+	len() int
+}
+
+// priorityQueue pops the task with the highest priority first and keeps the submission order for equal priorities.
+// With a non-zero aging interval the priority of a queued task grows by one for every interval it waits,
+// so low priority tasks are never starved.
+type priorityQueue struct {
+	futures       []*Future
+	origin        time.Time
+	agingInterval time.Duration
+}
+
+func newPriorityQueue(agingInterval time.Duration) *priorityQueue {
+	return &priorityQueue{
+		origin:        time.Now(),
+		agingInterval: agingInterval,
+	}
+}
+
+func (pq *priorityQueue) push(future *Future) {
+	heap.Push((*priorityHeap)(pq), future)
+}
+
+func (pq *priorityQueue) pop() *Future {
+	if len(pq.futures) == 0 {
+		return nil
+	}
+	return heap.Pop((*priorityHeap)(pq)).(*Future)
+}
+
+func (pq *priorityQueue) popOldest() *Future {
+	if len(pq.futures) == 0 {
+		return nil
+	}
+	return heap.Remove((*priorityHeap)(pq), pq.oldest()).(*Future)
+}
+
+// oldestSeq returns the submission sequence of the task which was queued first, the queue must not be empty.
+func (pq *priorityQueue) oldestSeq() uint64 {
+	return pq.futures[pq.oldest()].seq
+}
+
+func (pq *priorityQueue) oldest() int {
+	oldest := 0
+	for i, future := range pq.futures {
+		if future.seq < pq.futures[oldest].seq {
+			oldest = i
+		}
+	}
+	return oldest
+}
+
+func (pq *priorityQueue) len() int {
+	return len(pq.futures)
+}
+
+// effectivePriority compares tasks as if all of them were aged until now; as every queued task ages at the same
+// rate, the order only depends on the priority and the enqueue time of the task.
+func (pq *priorityQueue) effectivePriority(future *Future) float64 {
+	if pq.agingInterval <= 0 {
+		return float64(future.priority)
+	}
+	return float64(future.priority) - float64(future.enqueuedAt.Sub(pq.origin))/float64(pq.agingInterval)
+}
+
+// priorityHeap implements heap.Interface for priorityQueue.
+type priorityHeap priorityQueue
+
+func (ph *priorityHeap) Len() int {
+	return len(ph.futures)
+}
+
+func (ph *priorityHeap) Less(i, j int) bool {
+	pq := (*priorityQueue)(ph)
+	left, right := pq.effectivePriority(ph.futures[i]), pq.effectivePriority(ph.futures[j])
+	if left != right {
+		return left > right
+	}
+	return ph.futures[i].seq < ph.futures[j].seq
+}
+
+func (ph *priorityHeap) Swap(i, j int) {
+	ph.futures[i], ph.futures[j] = ph.futures[j], ph.futures[i]
+}
+
+func (ph *priorityHeap) Push(x any) {
+	ph.futures = append(ph.futures, x.(*Future))
+}
+
+func (ph *priorityHeap) Pop() any {
+	last := len(ph.futures) - 1
+	future := ph.futures[last]
+	ph.futures[last] = nil
+	ph.futures = ph.futures[:last]
+	return future
+}
+
+// enqueue adds the future to the queue, the rejection policy tells how to proceed while the queue is full.
+// BLOCK and BLOCK_WITH_TIMEOUT wait for free space, DROP_OLDEST removes the oldest queued task and the other
+// policies return ErrQueueFull.
+func (wp *WorkerPoolAdapter) enqueue(future *Future, policy RejectionPolicy) error {
+	var timeout <-chan time.Time
+	var evicted *Future
+
+	wp.queueMutex.Lock()
+	for {
+		// Tasks waiting behind their key were accepted before the queue was closed, they are still executed.
+		if (wp.queueClosed && !future.keyed) || wp.IsWorkerPoolStopped() {
+			wp.queueMutex.Unlock()
+			return ErrWorkerPoolStopped
+		}
+		tenantFull := wp.queue.isTenantFull(future)
+		if !tenantFull && wp.queue.len() < int(wp.maxTasks) {
+			break
+		}
+		if policy == DROP_OLDEST {
+			// A full tenant makes space with its own tasks, so it can't evict the tasks of the other tenants.
+			if tenantFull {
+				evicted = wp.queue.popOldestOf(wp.queue.tenants[future.tenant])
+			} else {
+				evicted = wp.queue.popOldest()
+			}
+			if evicted != nil {
+				break
+			}
+		}
+		if policy != BLOCK && policy != BLOCK_WITH_TIMEOUT {
+			wp.queueMutex.Unlock()
+			return ErrQueueFull
+		}
+		if policy == BLOCK_WITH_TIMEOUT && timeout == nil {
+			timer := time.NewTimer(wp.blockTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		changed := wp.queueChangedLocked()
+		wp.queueMutex.Unlock()
+		select {
+		case <-changed:
+		case <-timeout:
+			return ErrQueueFull
+		case <-wp.ctx.Done():
+			return ErrWorkerPoolStopped
+		}
+		wp.queueMutex.Lock()
+	}
+	wp.taskTracker.add(future)
+	future.enqueuedAt = time.Now()
+	wp.queue.push(future)
+	wp.notifyQueueChangedLocked()
+	wp.queueMutex.Unlock()
+
+	if evicted != nil {
+		wp.taskTracker.remove(evicted)
+		wp.rejectTask(evicted, DROP_OLDEST)
+	}
+	if wp.hooks.OnTaskQueued != nil {
+		wp.hooks.OnTaskQueued(future.Task())
+	}
+	return nil
+}
+
+// dequeue pops the next task for a worker which received a queue token.
+func (wp *WorkerPoolAdapter) dequeue() *Future {
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+
+	wp.tokensOutstanding--
+	future := wp.queue.pop()
+	if future != nil {
+		wp.executingTasks++
+		wp.queue.started(future)
+	}
+	wp.notifyQueueChangedLocked()
+	return future
+}
+
+// closeQueue stops accepting new tasks, the dispatcher closes the task channel once the queue is empty.
+func (wp *WorkerPoolAdapter) closeQueue() {
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+
+	wp.queueClosed = true
+	wp.notifyQueueChangedLocked()
+}
+
+// clearQueue removes all the tasks from the queue.
+func (wp *WorkerPoolAdapter) clearQueue() {
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+
+	for wp.queue.len() > 0 {
+		wp.queue.release(wp.queue.pop().tenant)
+	}
+	wp.notifyQueueChangedLocked()
+}
+
+// addKeyedWaiting updates the number of tasks waiting behind their key.
+func (wp *WorkerPoolAdapter) addKeyedWaiting(delta int) {
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+
+	wp.keyedWaiting.Add(int32(delta))
+	wp.notifyQueueChangedLocked()
+}
+
+func (wp *WorkerPoolAdapter) queueLength() int {
+	return wp.queue.len()
+}
+
+// dispatchTasks sends a queue token to a worker for every queued task. The task is popped only once the worker
+// executes the token, so the queue order is applied at the time a worker is ready to run it.
+// With an adaptive concurrency limiter, no token is sent while the limit of executing tasks is reached.
+// No token is sent while the pool is paused, a token waiting for a worker is taken back when the pool is paused.
+func (wp *WorkerPoolAdapter) dispatchTasks() {
+	defer wp.tasks.StopWriting()
+
+	for {
+		wp.queueMutex.Lock()
+		for wp.queue.len() <= wp.tokensOutstanding || !wp.hasTaskSlotLocked() || wp.paused.Load() {
+			if wp.queueClosed && wp.queue.len() <= wp.tokensOutstanding && wp.keyedWaiting.Load() == 0 {
+				wp.queueMutex.Unlock()
+				return
+			}
+
+			changed := wp.queueChangedLocked()
+			wp.queueMutex.Unlock()
+			select {
+			case <-changed:
+			case <-wp.ctx.Done():
+				return
+			}
+			wp.queueMutex.Lock()
+		}
+		wp.tokensOutstanding++
+		paused := wp.pausedSignalLocked()
+		wp.queueMutex.Unlock()
+
+		select {
+		case wp.tasks <- wp.newQueueToken():
+		case <-paused:
+			wp.queueMutex.Lock()
+			wp.tokensOutstanding--
+			wp.queueMutex.Unlock()
+		case <-wp.ctx.Done():
+			return
+		}
+	}
+}
+
+// newQueueToken creates the task received by the workers. The token is bound to the popped task on its first
+// execution, as SINGLE_TASK_WORKER executes the same task repeatedly. A task which is retried is released
+// by the token, the retry is executed from a new token. The failures of the task are logged by the token,
+// the workers would only know the token function.
+func (wp *WorkerPoolAdapter) newQueueToken() *gr_worker.Task {
+	var future *Future
+	dequeued := false
+	return gr_worker.NewTask(func() error {
+		if !dequeued {
+			dequeued = true
+			if future = wp.dequeue(); future != nil {
+				defer wp.releaseTaskSlot(future)
+			}
+		}
+		if future == nil {
+			return nil
+		}
+		wp.idleWorkerCount.Add(-1)
+		defer wp.idleWorkerCount.Add(1)
+		task := future.Task()
+		retried, err := wp.addConcurrencyDetailsToNewTask(future)
+		if retried {
+			future = nil
+		}
+		wp.logTaskFailure(task, err)
+		return nil
+	})
+}
+
+func (wp *WorkerPoolAdapter) queueChangedLocked() <-chan struct{} {
+	if wp.queueChanged == nil {
+		wp.queueChanged = make(chan struct{})
+	}
+	return wp.queueChanged
+}
+
+func (wp *WorkerPoolAdapter) notifyQueueChangedLocked() {
+	if wp.queueChanged != nil {
+		close(wp.queueChanged)
+		wp.queueChanged = nil
+	}
+}
@@ -0,0 +1,81 @@
+package worker_pool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolAdapter_AddTaskWithPriority(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(5))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	release := make(chan struct{})
+	wp.AddTask(func() { <-release })
+	time.Sleep(10 * time.Millisecond)
+
+	var mutex sync.Mutex
+	order := make([]int, 0, 4)
+	record := func(priority int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, priority)
+	}
+	for _, priority := range []int{1, 5, 1, 10} {
+		if !wp.AddTaskWithPriority(priority, record, priority) {
+			t.Fatalf("task with priority %d not added to worker pool", priority)
+		}
+	}
+	close(release)
+	wp.WaitAndStop()
+
+	expected := []int{10, 5, 1, 1}
+	if len(order) != len(expected) {
+		t.Fatalf("unexpected executed tasks, got: %v, want: %v", order, expected)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("tasks are not executed in priority order, got: %v, want: %v", order, expected)
+		}
+	}
+}
+
+func TestWorkerPoolAdapter_AddTaskWithPriority_RespectsMaxTasks(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(1))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	release := make(chan struct{})
+	defer close(release)
+	wp.AddTask(func() { <-release })
+	time.Sleep(10 * time.Millisecond)
+
+	if !wp.AddTaskIfSpaceAvailable(func() {}) {
+		t.Error("task not added to worker pool")
+	}
+	if wp.AddTaskIfSpaceAvailable(func() {}) {
+		t.Error("task added to worker pool but space doesn't exist")
+	}
+}
+
+func TestPriorityQueue_Aging(t *testing.T) {
+	pq := newPriorityQueue(time.Second)
+	old := &Future{priority: 0, seq: 1, enqueuedAt: pq.origin}
+	recent := &Future{priority: 2, seq: 2, enqueuedAt: pq.origin.Add(3 * time.Second)}
+	pq.push(recent)
+	pq.push(old)
+
+	if first := pq.pop(); first != old {
+		t.Error("aged low priority task is not popped first")
+	}
+	if second := pq.pop(); second != recent {
+		t.Error("recent high priority task is not popped second")
+	}
+	if pq.pop() != nil {
+		t.Error("empty queue returned a task")
+	}
+}
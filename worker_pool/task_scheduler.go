@@ -0,0 +1,189 @@
+package worker_pool
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// scheduledTask is a task waiting in the task scheduler until it is due.
+type scheduledTask struct {
+	future *Future
+	dueAt  time.Time
+	seq    uint64
+	index  int
+}
+
+// taskScheduler keeps the delayed tasks of a pool in a heap ordered by due time
+// and moves them into the task queue of the pool once they are due.
+type taskScheduler struct {
+	pool *WorkerPoolAdapter
+
+	mutex   sync.Mutex
+	tasks   scheduledHeap
+	nextSeq uint64
+	wakeup  chan struct{}
+	stopped bool
+	start   sync.Once
+	// length mirrors the number of tasks, so it can be read without the mutex.
+	length atomic.Int32
+}
+
+func newTaskScheduler(pool *WorkerPoolAdapter) *taskScheduler {
+	return &taskScheduler{
+		pool:   pool,
+		wakeup: make(chan struct{}, 1),
+	}
+}
+
+// schedule adds the future to the scheduler, it returns false if the scheduler is stopped.
+func (ts *taskScheduler) schedule(future *Future, dueAt time.Time) bool {
+	ts.start.Do(func() { go ts.run() })
+
+	task := &scheduledTask{future: future, dueAt: dueAt, index: -1}
+	future.setOnCancel(func() { ts.remove(task) })
+
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	if ts.stopped {
+		return false
+	}
+
+	ts.nextSeq++
+	task.seq = ts.nextSeq
+	heap.Push(&ts.tasks, task)
+	ts.length.Store(int32(len(ts.tasks)))
+	if task.index == 0 {
+		ts.notify()
+	}
+	return true
+}
+
+// remove deletes a cancelled task which is not due yet.
+func (ts *taskScheduler) remove(task *scheduledTask) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	if task.index >= 0 {
+		heap.Remove(&ts.tasks, task.index)
+		ts.length.Store(int32(len(ts.tasks)))
+	}
+}
+
+// stop rejects new tasks and returns the futures of all the tasks which are not due yet, in due order.
+func (ts *taskScheduler) stop() []*Future {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	ts.stopped = true
+	ts.notify()
+
+	futures := make([]*Future, 0, len(ts.tasks))
+	for len(ts.tasks) > 0 {
+		futures = append(futures, heap.Pop(&ts.tasks).(*scheduledTask).future)
+	}
+	ts.length.Store(0)
+	return futures
+}
+
+func (ts *taskScheduler) len() int {
+	return int(ts.length.Load())
+}
+
+func (ts *taskScheduler) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		due, wait, stopped := ts.popDueTasks(time.Now())
+		if stopped {
+			return
+		}
+		for _, future := range due {
+			if err := ts.pool.writeTask(future, BLOCK); err != nil {
+				ts.pool.dropFutures(ts.pool.finishRetries([]*Future{future}))
+			}
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-ts.wakeup:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-ts.pool.ctx.Done():
+			return
+		}
+	}
+}
+
+// popDueTasks removes the tasks due before now and returns the time to wait for the next task.
+func (ts *taskScheduler) popDueTasks(now time.Time) ([]*Future, time.Duration, bool) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	if ts.stopped {
+		return nil, 0, true
+	}
+
+	var due []*Future
+	for len(ts.tasks) > 0 && !ts.tasks[0].dueAt.After(now) {
+		due = append(due, heap.Pop(&ts.tasks).(*scheduledTask).future)
+	}
+	ts.length.Store(int32(len(ts.tasks)))
+
+	wait := time.Hour
+	if len(ts.tasks) > 0 {
+		wait = ts.tasks[0].dueAt.Sub(now)
+	}
+	return due, wait, false
+}
+
+func (ts *taskScheduler) notify() {
+	select {
+	case ts.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// scheduledHeap implements heap.Interface ordered by due time and then by scheduling order.
+type scheduledHeap []*scheduledTask
+
+func (sh scheduledHeap) Len() int {
+	return len(sh)
+}
+
+func (sh scheduledHeap) Less(i, j int) bool {
+	if !sh[i].dueAt.Equal(sh[j].dueAt) {
+		return sh[i].dueAt.Before(sh[j].dueAt)
+	}
+	return sh[i].seq < sh[j].seq
+}
+
+func (sh scheduledHeap) Swap(i, j int) {
+	sh[i], sh[j] = sh[j], sh[i]
+	sh[i].index = i
+	sh[j].index = j
+}
+
+func (sh *scheduledHeap) Push(x any) {
+	task := x.(*scheduledTask)
+	task.index = len(*sh)
+	*sh = append(*sh, task)
+}
+
+func (sh *scheduledHeap) Pop() any {
+	old := *sh
+	last := len(old) - 1
+	task := old[last]
+	old[last] = nil
+	task.index = -1
+	*sh = old[:last]
+	return task
+}
@@ -0,0 +1,99 @@
+package worker_pool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolAdapter_AddTaskAfter(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1), WithMaxTasks(2))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	submittedAt := time.Now()
+	future, err := wp.SubmitAfter(30*time.Millisecond, func() time.Time { return time.Now() })
+	if err != nil {
+		t.Fatalf("error scheduling task: %v", err)
+	}
+
+	results, err := future.Result()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if executedAt := results[0].(time.Time); executedAt.Sub(submittedAt) < 30*time.Millisecond {
+		t.Errorf("task executed before it was due; delay: %v", executedAt.Sub(submittedAt))
+	}
+}
+
+func TestWorkerPoolAdapter_AddTaskAt_Order(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(2))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	now := time.Now()
+	second, _ := wp.SubmitAt(now.Add(40*time.Millisecond), func() time.Time { return time.Now() })
+	first, _ := wp.SubmitAt(now.Add(20*time.Millisecond), func() time.Time { return time.Now() })
+	if count := wp.ScheduledTaskCount(); count != 2 {
+		t.Errorf("unexpected scheduled tasks; got: %d, want: 2", count)
+	}
+
+	firstResults, _ := first.Result()
+	secondResults, _ := second.Result()
+	if !firstResults[0].(time.Time).Before(secondResults[0].(time.Time)) {
+		t.Error("scheduled tasks are not executed in due order")
+	}
+}
+
+func TestWorkerPoolAdapter_AddTaskAfter_Cancel(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	var executed atomic.Bool
+	future, err := wp.SubmitAfter(20*time.Millisecond, func() { executed.Store(true) })
+	if err != nil {
+		t.Fatalf("error scheduling task: %v", err)
+	}
+	if !future.Cancel() {
+		t.Error("scheduled task is not cancelled")
+	}
+	if count := wp.ScheduledTaskCount(); count != 0 {
+		t.Errorf("cancelled task is still scheduled; scheduled tasks: %d", count)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if executed.Load() {
+		t.Error("cancelled scheduled task is executed")
+	}
+}
+
+func TestWorkerPoolAdapter_Drain_DropsScheduledTasks(t *testing.T) {
+	wp, err := NewWorkerPool(WithMaxWorkers(1))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	future, err := wp.SubmitAfter(time.Hour, func() {})
+	if err != nil {
+		t.Fatalf("error scheduling task: %v", err)
+	}
+
+	notExecuted, err := wp.Drain(context.Background())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(notExecuted) != 1 || notExecuted[0] != future.Task() {
+		t.Errorf("scheduled task is not returned as not executed; got: %v", notExecuted)
+	}
+	if _, err := future.Result(); err != ErrTaskDropped {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrTaskDropped)
+	}
+}
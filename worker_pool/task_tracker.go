@@ -0,0 +1,111 @@
+package worker_pool
+
+import (
+	"sort"
+	"sync"
+)
+
+// taskTracker keeps the queued tasks and counts the running tasks of a pool,
+// it signals when none of them are left.
+type taskTracker struct {
+	mutex   sync.Mutex
+	nextSeq uint64
+	queued  map[*Future]struct{}
+	running int
+	idle    chan struct{}
+}
+
+func newTaskTracker() *taskTracker {
+	idle := make(chan struct{})
+	close(idle)
+	return &taskTracker{
+		queued: make(map[*Future]struct{}),
+		idle:   idle,
+	}
+}
+
+// add registers a new queued task, a task which is already queued keeps its submission order.
+func (tt *taskTracker) add(future *Future) {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+
+	if _, ok := tt.queued[future]; ok {
+		return
+	}
+	if len(tt.queued)+tt.running == 0 {
+		tt.idle = make(chan struct{})
+	}
+	tt.nextSeq++
+	future.seq = tt.nextSeq
+	tt.queued[future] = struct{}{}
+}
+
+// remove unregisters a queued task which will never run, it is a no-op for an already removed task.
+func (tt *taskTracker) remove(future *Future) {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+
+	if _, ok := tt.queued[future]; !ok {
+		return
+	}
+	delete(tt.queued, future)
+	tt.closeIdleIfRequired()
+}
+
+// start moves a queued task to the running tasks.
+func (tt *taskTracker) start(future *Future) {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+
+	delete(tt.queued, future)
+	tt.running++
+}
+
+// done unregisters a finished task.
+func (tt *taskTracker) done() {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+
+	tt.running--
+	tt.closeIdleIfRequired()
+}
+
+// removeQueued unregisters all the queued tasks and returns them in submission order.
+func (tt *taskTracker) removeQueued() []*Future {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+
+	futures := make([]*Future, 0, len(tt.queued))
+	for future := range tt.queued {
+		futures = append(futures, future)
+	}
+	sort.Slice(futures, func(i, j int) bool { return futures[i].seq < futures[j].seq })
+
+	tt.queued = make(map[*Future]struct{})
+	tt.closeIdleIfRequired()
+	return futures
+}
+
+// wait returns a channel which is closed once there are no queued or running tasks.
+func (tt *taskTracker) wait() <-chan struct{} {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	return tt.idle
+}
+
+func (tt *taskTracker) counts() (queued int, running int) {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	return len(tt.queued), tt.running
+}
+
+func (tt *taskTracker) closeIdleIfRequired() {
+	if len(tt.queued)+tt.running != 0 {
+		return
+	}
+	select {
+	case <-tt.idle:
+	default:
+		close(tt.idle)
+	}
+}
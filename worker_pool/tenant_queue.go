@@ -0,0 +1,244 @@
+package worker_pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/vd09/gr_worker"
+)
+
+const (
+	// DefaultTenant is the tenant of the tasks which are not submitted for a tenant.
+	DefaultTenant       = ""
+	DefaultTenantWeight = 1
+)
+
+var ErrTenantConfig = errors.New("tenant weight and max tasks can't be less than zero")
+
+// TenantConfig configures the share of the pool of a tenant. A tenant with a weight of 2 gets twice as many tasks
+// dispatched as a tenant with a weight of 1 while both have queued tasks. MaxTasks caps the queued tasks
+// of the tenant, zero only applies the max tasks of the pool. Tenants without configuration use the zero value,
+// which has the default weight.
+type TenantConfig struct {
+	Weight   int
+	MaxTasks int
+}
+
+func (c TenantConfig) validate() error {
+	if c.Weight < 0 || c.MaxTasks < 0 {
+		return ErrTenantConfig
+	}
+	return nil
+}
+
+// TenantStats is a snapshot of the tasks of a tenant.
+type TenantStats struct {
+	QueuedTasks  int
+	RunningTasks int
+	// RejectedTasks counts the tasks of the tenant refused by the full queue, see Stats.RejectedTasks.
+	// The tasks of an unconfigured tenant are only counted while it has queued or running tasks.
+	RejectedTasks uint64
+}
+
+// tenantQueue holds the queued tasks of a tenant in priority order.
+type tenantQueue struct {
+	config   TenantConfig
+	queue    *priorityQueue
+	deficit  int
+	running  int
+	rejected uint64
+}
+
+func (tq *tenantQueue) weight() int {
+	if tq.config.Weight == 0 {
+		return DefaultTenantWeight
+	}
+	return tq.config.Weight
+}
+
+// fairQueue shares the pool between the tenants with deficit round robin. Every task costs one, a tenant receives
+// its weight as quantum on its turn and the tasks are popped from it until its deficit is spent or its queue
+// is empty. Within a tenant, the tasks are popped by priority. It is guarded by the queue mutex of the pool,
+// only its length can be read without it.
+type fairQueue struct {
+	agingInterval time.Duration
+	configs       map[string]TenantConfig
+	tenants       map[string]*tenantQueue
+	// active holds the tenants with queued tasks in round robin order, current is the one whose turn it is.
+	active  []*tenantQueue
+	current int
+	length  atomic.Int32
+}
+
+func newFairQueue(agingInterval time.Duration, configs map[string]TenantConfig) *fairQueue {
+	fq := &fairQueue{
+		agingInterval: agingInterval,
+		configs:       configs,
+		tenants:       make(map[string]*tenantQueue),
+	}
+	for name := range configs {
+		fq.tenant(name)
+	}
+	return fq
+}
+
+// tenant returns the queue of the tenant, an unconfigured tenant is added for its first task.
+func (fq *fairQueue) tenant(name string) *tenantQueue {
+	tq, ok := fq.tenants[name]
+	if !ok {
+		tq = &tenantQueue{
+			config: fq.configs[name],
+			queue:  newPriorityQueue(fq.agingInterval),
+		}
+		fq.tenants[name] = tq
+	}
+	return tq
+}
+
+func (fq *fairQueue) push(future *Future) {
+	tq := fq.tenant(future.tenant)
+	if tq.queue.len() == 0 {
+		fq.active = append(fq.active, tq)
+	}
+	tq.queue.push(future)
+	fq.length.Add(1)
+}
+
+func (fq *fairQueue) pop() *Future {
+	if len(fq.active) == 0 {
+		return nil
+	}
+	if fq.current >= len(fq.active) {
+		fq.current = 0
+	}
+
+	tq := fq.active[fq.current]
+	if tq.deficit <= 0 {
+		tq.deficit = tq.weight()
+	}
+	future := tq.queue.pop()
+	tq.deficit--
+	fq.length.Add(-1)
+
+	switch {
+	case tq.queue.len() == 0:
+		fq.deactivate(fq.current)
+	case tq.deficit <= 0:
+		fq.current++
+	}
+	return future
+}
+
+// popOldest removes the task which was queued first among all the tenants.
+func (fq *fairQueue) popOldest() *Future {
+	var oldest *tenantQueue
+	var oldestSeq uint64
+	for _, tq := range fq.active {
+		if seq := tq.queue.oldestSeq(); oldest == nil || seq < oldestSeq {
+			oldest, oldestSeq = tq, seq
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+	return fq.popOldestOf(oldest)
+}
+
+// popOldestOf removes the task of the tenant which was queued first.
+func (fq *fairQueue) popOldestOf(tq *tenantQueue) *Future {
+	future := tq.queue.popOldest()
+	if future == nil {
+		return nil
+	}
+	fq.length.Add(-1)
+	if tq.queue.len() == 0 {
+		for i, active := range fq.active {
+			if active == tq {
+				fq.deactivate(i)
+				break
+			}
+		}
+	}
+	fq.release(future.tenant)
+	return future
+}
+
+// release deletes an unconfigured tenant once it has no queued and no running tasks.
+func (fq *fairQueue) release(name string) {
+	if _, configured := fq.configs[name]; configured {
+		return
+	}
+	if tq, ok := fq.tenants[name]; ok && tq.queue.len() == 0 && tq.running == 0 {
+		delete(fq.tenants, name)
+	}
+}
+
+func (fq *fairQueue) deactivate(i int) {
+	fq.active[i].deficit = 0
+	fq.active = append(fq.active[:i], fq.active[i+1:]...)
+	if i < fq.current {
+		fq.current--
+	}
+}
+
+func (fq *fairQueue) len() int {
+	return int(fq.length.Load())
+}
+
+// isTenantFull reports whether the tenant of the future has reached its own cap of queued tasks.
+func (fq *fairQueue) isTenantFull(future *Future) bool {
+	tq, ok := fq.tenants[future.tenant]
+	return ok && tq.config.MaxTasks > 0 && tq.queue.len() >= tq.config.MaxTasks
+}
+
+func (fq *fairQueue) started(future *Future) {
+	fq.tenant(future.tenant).running++
+}
+
+func (fq *fairQueue) finished(future *Future) {
+	if tq, ok := fq.tenants[future.tenant]; ok {
+		tq.running--
+		fq.release(future.tenant)
+	}
+}
+
+func (fq *fairQueue) rejected(future *Future) {
+	if tq, ok := fq.tenants[future.tenant]; ok {
+		tq.rejected++
+	}
+}
+
+func (fq *fairQueue) stats() map[string]TenantStats {
+	stats := make(map[string]TenantStats, len(fq.tenants))
+	for name, tq := range fq.tenants {
+		stats[name] = TenantStats{
+			QueuedTasks:   tq.queue.len(),
+			RunningTasks:  tq.running,
+			RejectedTasks: tq.rejected,
+		}
+	}
+	return stats
+}
+
+func (wp *WorkerPoolAdapter) AddTaskForTenant(tenant string, taskFunc interface{}, params ...interface{}) bool {
+	_, err := wp.SubmitForTenant(tenant, taskFunc, params...)
+	return err == nil
+}
+
+// SubmitForTenant adds a task to the queue of the tenant. The queued tasks of the tenants are dispatched
+// to the workers in proportion to the weights of the tenants, so a tenant with many queued tasks can't starve
+// the other ones. Once the tenant reaches its own max tasks, the rejection policy of the pool is applied.
+func (wp *WorkerPoolAdapter) SubmitForTenant(tenant string, taskFunc interface{}, params ...interface{}) (*Future, error) {
+	future := newFuture(gr_worker.NewTask(taskFunc, params...))
+	future.tenant = tenant
+	return wp.submitFuture(future)
+}
+
+// TenantStats returns a snapshot of the configured tenants and of the tenants with queued or running tasks.
+// Tasks submitted without a tenant are reported for DefaultTenant.
+func (wp *WorkerPoolAdapter) TenantStats() map[string]TenantStats {
+	wp.queueMutex.Lock()
+	defer wp.queueMutex.Unlock()
+	return wp.queue.stats()
+}
@@ -0,0 +1,115 @@
+package worker_pool
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/logger"
+)
+
+func TestFairQueue_DeficitRoundRobin(t *testing.T) {
+	fq := newFairQueue(0, map[string]TenantConfig{"a": {Weight: 2}})
+
+	seq := uint64(0)
+	push := func(tenant string, count int) {
+		for i := 0; i < count; i++ {
+			seq++
+			future := newFuture(gr_worker.NewTask(func() {}))
+			future.tenant, future.seq = tenant, seq
+			fq.push(future)
+		}
+	}
+	push("a", 6)
+	push("b", 4)
+
+	var order strings.Builder
+	for fq.len() > 0 {
+		order.WriteString(fq.pop().tenant)
+	}
+	if expected := "aabaabaabb"; order.String() != expected {
+		t.Errorf("unexpected dispatch order, got: %s, want: %s", order.String(), expected)
+	}
+	if fq.pop() != nil {
+		t.Error("task popped from an empty queue")
+	}
+}
+
+func TestWorkerPoolAdapter_SubmitForTenant(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithMaxTasks(10), WithLogger(logger.Discard),
+		WithRejectionPolicy(REJECT), WithTenant("noisy", TenantConfig{MaxTasks: 2}))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	wp.AddTaskForTenant("quiet", func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	for i := 0; i < 2; i++ {
+		if !wp.AddTaskForTenant("noisy", func() {}) {
+			t.Fatalf("task %d of the tenant is rejected below its max tasks", i)
+		}
+	}
+	if _, err := wp.SubmitForTenant("noisy", func() {}); err != ErrQueueFull {
+		t.Errorf("unexpected error above the max tasks of the tenant, got: %v, want: %v", err, ErrQueueFull)
+	}
+	if !wp.AddTaskForTenant("quiet", func() {}) {
+		t.Error("task of another tenant is rejected")
+	}
+
+	stats := wp.TenantStats()
+	close(release)
+
+	expected := map[string]TenantStats{
+		"noisy": {QueuedTasks: 2, RejectedTasks: 1},
+		"quiet": {QueuedTasks: 1, RunningTasks: 1},
+	}
+	if len(stats) != len(expected) {
+		t.Errorf("unexpected tenants: %+v", stats)
+	}
+	for tenant, want := range expected {
+		if got := stats[tenant]; got != want {
+			t.Errorf("unexpected stats of tenant %q\ngot:  %+v\nwant: %+v", tenant, got, want)
+		}
+	}
+}
+
+func TestWorkerPoolAdapter_TenantRelease(t *testing.T) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(2), WithMaxTasks(10), WithLogger(logger.Discard),
+		WithTenant("configured", TenantConfig{Weight: 2}))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	var futures []*Future
+	for i := 0; i < 20; i++ {
+		future, err := wp.SubmitForTenant(fmt.Sprintf("tenant-%d", i), func() {})
+		if err != nil {
+			t.Fatalf("error submitting task: %v", err)
+		}
+		futures = append(futures, future)
+	}
+	for _, future := range futures {
+		_ = future.Wait(testContext(t))
+	}
+
+	// Only the configured tenant is kept once the tasks of the other tenants are finished.
+	waitForCondition(t, func() bool { return len(wp.TenantStats()) == 1 }, "idle tenants are not released")
+	if _, ok := wp.TenantStats()["configured"]; !ok {
+		t.Error("configured tenant is released")
+	}
+}
+
+func TestWorkerPoolAdapter_ValidateTenant(t *testing.T) {
+	if _, err := NewWorkerPool(WithTenant("tenant", TenantConfig{Weight: -1})); err != ErrTenantConfig {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrTenantConfig)
+	}
+}
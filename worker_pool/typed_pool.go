@@ -0,0 +1,98 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vd09/gr_worker"
+)
+
+var ErrNilTaskFunc = errors.New("task function can't be nil")
+
+// TypedFunc is the task function executed by a TypedPool.
+type TypedFunc[In, Out any] func(ctx context.Context, input In) (Out, error)
+
+// TypedPool is a type safe worker pool which executes a single task function for every submitted input.
+// It runs on top of WorkerPoolAdapter, so all the worker strategies and options are supported,
+// but the task function is called without reflection. With SINGLE_TASK_WORKER, the repeated executions
+// of an input are not part of its TypedFuture, its output is the one of the execution which finished it.
+type TypedPool[In, Out any] struct {
+	pool *WorkerPoolAdapter
+	fn   TypedFunc[In, Out]
+}
+
+// TypedFuture is the handle of an input submitted to a TypedPool.
+type TypedFuture[Out any] struct {
+	future *Future
+	output Out
+}
+
+func NewTypedPool[In, Out any](fn TypedFunc[In, Out], options ...Option) (*TypedPool[In, Out], error) {
+	if fn == nil {
+		return nil, ErrNilTaskFunc
+	}
+
+	pool, err := NewWorkerPoolAdapter(options...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedPool[In, Out]{
+		pool: pool,
+		fn:   fn,
+	}, nil
+}
+
+// Submit adds the input to the pool and returns its TypedFuture, it blocks until space is available in the pool.
+// The task function receives a context derived from both the given context and the pool context.
+func (tp *TypedPool[In, Out]) Submit(ctx context.Context, input In) (*TypedFuture[Out], error) {
+	typedFuture := &TypedFuture[Out]{}
+	var future *Future
+	future = newFuture(gr_worker.NewTaskWithContext(ctx, func(taskCtx context.Context) error {
+		output, err := tp.fn(taskCtx, input)
+		// SINGLE_TASK_WORKER executes the task again once its future is finished,
+		// only the runs before the future is finished provide its output.
+		if futureState(future.state.Load()) == futureRunning {
+			typedFuture.output = output
+		}
+		return err
+	}))
+	if _, err := tp.pool.submitFuture(future); err != nil {
+		return nil, err
+	}
+
+	typedFuture.future = future
+	return typedFuture, nil
+}
+
+func (tp *TypedPool[In, Out]) IsWorkerPoolStopped() bool {
+	return tp.pool.IsWorkerPoolStopped()
+}
+
+func (tp *TypedPool[In, Out]) Stop() {
+	tp.pool.Stop()
+}
+
+func (tp *TypedPool[In, Out]) WaitAndStop() {
+	tp.pool.WaitAndStop()
+}
+
+// Done returns a channel which is closed once the task is finished or cancelled.
+func (tf *TypedFuture[Out]) Done() <-chan struct{} {
+	return tf.future.Done()
+}
+
+// Wait blocks until the task is finished or the context is done.
+func (tf *TypedFuture[Out]) Wait(ctx context.Context) error {
+	return tf.future.Wait(ctx)
+}
+
+// Result blocks until the task is finished and returns the output of the task function.
+func (tf *TypedFuture[Out]) Result() (Out, error) {
+	_, err := tf.future.Result()
+	return tf.output, err
+}
+
+// Cancel removes a task which is not yet picked by any worker.
+func (tf *TypedFuture[Out]) Cancel() bool {
+	return tf.future.Cancel()
+}
@@ -0,0 +1,124 @@
+package worker_pool
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vd09/gr_worker/logger"
+	"github.com/vd09/gr_worker/worker"
+)
+
+func TestTypedPool_Submit(t *testing.T) {
+	tp, err := NewTypedPool(func(ctx context.Context, input int) (string, error) {
+		if input < 0 {
+			return "", errors.New("negative input")
+		}
+		return strconv.Itoa(input * 2), nil
+	}, WithMaxWorkers(3), WithMaxTasks(5))
+	if err != nil {
+		t.Fatalf("error creating typed pool: %v", err)
+	}
+	defer tp.Stop()
+
+	futures := make([]*TypedFuture[string], 0, 5)
+	for i := 0; i < 5; i++ {
+		future, err := tp.Submit(context.Background(), i)
+		if err != nil {
+			t.Fatalf("error submitting input %d: %v", i, err)
+		}
+		futures = append(futures, future)
+	}
+
+	for i, future := range futures {
+		output, err := future.Result()
+		if err != nil {
+			t.Errorf("unexpected error for input %d: %v", i, err)
+		}
+		if output != strconv.Itoa(i*2) {
+			t.Errorf("unexpected output for input %d, got: %v, want: %v", i, output, i*2)
+		}
+	}
+
+	future, err := tp.Submit(context.Background(), -1)
+	if err != nil {
+		t.Fatalf("error submitting input: %v", err)
+	}
+	if _, err := future.Result(); err == nil {
+		t.Error("error of the task function is not returned")
+	}
+}
+
+func TestTypedPool_SingleTaskWorker(t *testing.T) {
+	var executions atomic.Int32
+	tp, err := NewTypedPool(func(ctx context.Context, input int) (int, error) {
+		return int(executions.Add(1)), nil
+	}, WithMaxWorkers(1), WithWorkerStrategy(worker.SINGLE_TASK_WORKER), WithLogger(logger.Discard))
+	if err != nil {
+		t.Fatalf("error creating typed pool: %v", err)
+	}
+	defer tp.Stop()
+
+	future, err := tp.Submit(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("error submitting input: %v", err)
+	}
+	output, err := future.Result()
+	if err != nil || output != 1 {
+		t.Errorf("unexpected result, got: %v, %v, want: 1", output, err)
+	}
+	// The worker keeps executing the task, the output of the future is not changed anymore.
+	for executions.Load() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	if output, _ := future.Result(); output != 1 {
+		t.Errorf("output is changed by a repeated execution, got: %v, want: 1", output)
+	}
+}
+
+func TestTypedPool_NilFunc(t *testing.T) {
+	if _, err := NewTypedPool[int, int](nil); err != ErrNilTaskFunc {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrNilTaskFunc)
+	}
+}
+
+func BenchmarkWorkerPoolAdapter_Submit(b *testing.B) {
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(4), WithMaxTasks(1024))
+	if err != nil {
+		b.Fatalf("error creating worker pool: %v", err)
+	}
+	defer wp.Stop()
+
+	double := func(input int) (int, error) { return input * 2, nil }
+	futures := make([]*Future, b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		futures[i], _ = wp.Submit(double, i)
+	}
+	for _, future := range futures {
+		future.Result()
+	}
+}
+
+func BenchmarkTypedPool_Submit(b *testing.B) {
+	tp, err := NewTypedPool(func(ctx context.Context, input int) (int, error) {
+		return input * 2, nil
+	}, WithMaxWorkers(4), WithMaxTasks(1024))
+	if err != nil {
+		b.Fatalf("error creating typed pool: %v", err)
+	}
+	defer tp.Stop()
+
+	ctx := context.Background()
+	futures := make([]*TypedFuture[int], b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		futures[i], _ = tp.Submit(ctx, i)
+	}
+	for _, future := range futures {
+		future.Result()
+	}
+}
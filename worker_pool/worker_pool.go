@@ -1,9 +1,45 @@
 package worker_pool
 
+import (
+	"context"
+	"time"
+
+	"github.com/vd09/gr_worker"
+)
+
 type WorkerPool interface {
 	AddTask(taskFunc interface{}, params ...interface{}) bool
 	AddTaskIfSpaceAvailable(taskFunc interface{}, params ...interface{}) bool
+	AddTaskWithPriority(priority int, taskFunc interface{}, params ...interface{}) bool
+	AddTaskAfter(delay time.Duration, taskFunc interface{}, params ...interface{}) bool
+	AddTaskAt(at time.Time, taskFunc interface{}, params ...interface{}) bool
+	AddTaskWithRetry(policy RetryPolicy, taskFunc interface{}, params ...interface{}) bool
+	AddTaskWithKey(key string, taskFunc interface{}, params ...interface{}) bool
+	AddTaskForTenant(tenant string, taskFunc interface{}, params ...interface{}) bool
+	AddTaskWithContext(ctx context.Context, taskFunc interface{}, params ...interface{}) bool
+	Submit(taskFunc interface{}, params ...interface{}) (*Future, error)
+	SubmitWithContext(ctx context.Context, taskFunc interface{}, params ...interface{}) (*Future, error)
+	SubmitWithPriority(priority int, taskFunc interface{}, params ...interface{}) (*Future, error)
+	SubmitWithRetry(policy RetryPolicy, taskFunc interface{}, params ...interface{}) (*Future, error)
+	SubmitWithKey(key string, taskFunc interface{}, params ...interface{}) (*Future, error)
+	SubmitForTenant(tenant string, taskFunc interface{}, params ...interface{}) (*Future, error)
+	SubmitAfter(delay time.Duration, taskFunc interface{}, params ...interface{}) (*Future, error)
+	SubmitAt(at time.Time, taskFunc interface{}, params ...interface{}) (*Future, error)
+	NewGroup(ctx context.Context, options ...GroupOption) *Group
+	SetMaxWorkers(maxWorkers int32) error
+	SetMinWorkers(minWorkers int32) error
+	SetMaxTasks(maxTasks int32) error
+	Pause()
+	Resume()
+	IsPaused() bool
 	IsWorkerPoolStopped() bool
+	Stats() Stats
+	TenantStats() map[string]TenantStats
+	QueueWaitHistogram() Histogram
+	ExecutionHistogram() Histogram
 	Stop()
 	WaitAndStop()
+	WaitAndStopContext(ctx context.Context) error
+	WaitAndStopTimeout(timeout time.Duration) error
+	Drain(ctx context.Context) ([]*gr_worker.Task, error)
 }
@@ -2,11 +2,13 @@ package worker_pool
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	gr_variable "github.com/vd09/gr-variable"
+	"github.com/vd09/gr-variable/chan_variable"
 	"github.com/vd09/gr_worker"
 	"github.com/vd09/gr_worker/domain"
 	"github.com/vd09/gr_worker/logger"
@@ -21,27 +23,88 @@ const (
 	DefaultWorkerStrategy = worker.STANDARD_WORKER
 )
 
+// ErrorHandler receives every task which returned an error or panicked.
+// A panic is reported as a *gr_worker.PanicError.
+type ErrorHandler func(task *gr_worker.Task, err error)
+
+var (
+	ErrWorkerPoolStopped  = errors.New("worker pool is stopped")
+	ErrTaskContextExpired = errors.New("task context expired before execution")
+	ErrQueueFull          = errors.New("task queue is full")
+)
+
+// ShutdownError is returned when the pool is stopped before all the submitted tasks are finished.
+type ShutdownError struct {
+	Queued  int
+	Running int
+	Err     error
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("worker pool stopped with %d queued and %d running tasks: %v", e.Queued, e.Running, e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
 type WorkerPoolAdapter struct {
 	// context settings
-	ctx       context.Context
-	cancelCtx context.CancelFunc
-	logger    logger.Logger
+	ctx            context.Context
+	cancelCtx      context.CancelFunc
+	logger         logger.Logger
+	errorHandler   ErrorHandler
+	deadLetterSink DeadLetterSink
+	middlewares    []Middleware
+	taskHandler    TaskHandler
+	hooks          Hooks
 
 	// Atomic counters, should be placed first so alignment is guaranteed for atomic operations.
 	activeWorkerCount atomic.Int32
 	idleWorkerCount   atomic.Int32
 	stopped           atomic.Bool
+	paused            atomic.Bool
+	// workerLimit mirrors maxWorkers, so the limit can be read without the mutex.
+	workerLimit atomic.Int32
 
 	// Configurable settings
-	minWorkers  int32
-	maxWorkers  int32
-	maxTasks    int32
-	idleTimeout time.Duration
-	strategy    worker.WorkerStrategy
+	minWorkers      int32
+	maxWorkers      int32
+	maxTasks        int32
+	idleTimeout     time.Duration
+	taskTimeout     time.Duration
+	agingInterval   time.Duration
+	retryPolicy     *RetryPolicy
+	maxTasksPerKey  int32
+	rejectionPolicy RejectionPolicy
+	blockTimeout    time.Duration
+	tenants         map[string]TenantConfig
+	adaptive        *AdaptiveConcurrency
+	strategy        worker.WorkerStrategy
+	workerFactory   worker.WorkerFactory
 
 	// Private properties
-	mutex sync.Mutex
-	tasks gr_variable.GrChannel[*gr_worker.Task]
+	mutex       sync.Mutex
+	tasks       chan_variable.ChanVar[*gr_worker.Task]
+	taskTracker *taskTracker
+	scheduler   *taskScheduler
+	keyedTasks  *keyedTasks
+	limiter     *concurrencyLimiter
+	workers     sync.WaitGroup
+	poolWorkers map[*poolWorker]struct{}
+	counters    poolCounters
+
+	// Task queue, guarded by queueMutex
+	queueMutex   sync.Mutex
+	queue        *fairQueue
+	queueChanged chan struct{}
+	queueClosed  bool
+	// keyedWaiting counts the tasks waiting behind their key, the dispatcher keeps running for them
+	// while the pool is drained. It is changed under queueMutex, but can be read without it.
+	keyedWaiting      atomic.Int32
+	tokensOutstanding int
+	executingTasks    int
+	pausedSignal      chan struct{}
 }
 
 func NewWorkerPool(options ...Option) (WorkerPool, error) {
@@ -55,6 +118,7 @@ func NewWorkerPoolAdapter(options ...Option) (*WorkerPoolAdapter, error) {
 		maxTasks:    DefaultMaxTasks,
 		idleTimeout: DefaultIdleTimeout,
 		strategy:    DefaultWorkerStrategy,
+		logger:      logger.Std,
 	}
 	wp.stopped.Store(false)
 	wp.activeWorkerCount.Store(0)
@@ -72,10 +136,30 @@ func NewWorkerPoolAdapter(options ...Option) (*WorkerPoolAdapter, error) {
 	if wp.ctx == nil {
 		WithContext(context.Background())(wp)
 	}
+	if wp.workerFactory == nil {
+		wp.workerFactory, _ = worker.NewWorkerFactory(wp.strategy, wp.idleTimeout)
+	}
+	if wp.logger == nil {
+		wp.logger = logger.Discard
+	}
 	if wp.minWorkers < 0 {
 		wp.minWorkers = wp.maxWorkers
 	}
-	wp.tasks = gr_variable.NewGrChannelWithLength[*gr_worker.Task](int(wp.maxTasks))
+	wp.workerLimit.Store(wp.maxWorkers)
+	if wp.maxTasksPerKey == 0 {
+		wp.maxTasksPerKey = DefaultMaxTasksPerKey
+	}
+	if wp.adaptive != nil {
+		wp.limiter = newConcurrencyLimiter(*wp.adaptive, wp.minWorkers, wp.maxWorkers)
+	}
+	wp.taskHandler = chainMiddlewares(wp.middlewares)
+	wp.tasks = chan_variable.NewCharVar[*gr_worker.Task]()
+	wp.poolWorkers = make(map[*poolWorker]struct{})
+	wp.taskTracker = newTaskTracker()
+	wp.queue = newFairQueue(wp.agingInterval, wp.tenants)
+	wp.scheduler = newTaskScheduler(wp)
+	wp.keyedTasks = newKeyedTasks(wp)
+	go wp.dispatchTasks()
 
 	for i := int32(0); i < wp.minWorkers; i++ {
 		wp.startNewWorkerIfRequired()
@@ -87,109 +171,398 @@ func (wp *WorkerPoolAdapter) IsWorkerPoolStopped() bool {
 	return wp.stopped.Load()
 }
 
+// Stop cancels the pool context and drops all the queued and scheduled tasks,
+// their futures finish with ErrTaskDropped. Tasks waiting for a retry finish with the error of their last attempt.
 func (wp *WorkerPoolAdapter) Stop() {
 	wp.cancelCtx()
-	wp.stopped.Store(true)
-	wp.tasks.StopWriting()
+	wp.markStopped()
+	wp.closeQueue()
+	wp.dropScheduledTasks()
+	wp.dropKeyedTasks()
+	wp.dropQueuedTasks()
+	wp.clearQueue()
 }
 
+// WaitAndStop stops accepting new tasks and blocks until all the queued and running tasks are finished.
+// Scheduled tasks which are not due yet are dropped, tasks waiting for a retry are not retried anymore
+// and finish with the error of their last attempt.
 func (wp *WorkerPoolAdapter) WaitAndStop() {
-	wp.tasks.StopWriting()
-	for {
-		if wp.activeWorkerCount.Load() <= 0 {
-			wp.cancelCtx()
-			wp.stopped.Store(true)
-			return
+	_ = wp.WaitAndStopContext(context.Background())
+}
+
+// WaitAndStopTimeout is WaitAndStopContext with a timeout.
+func (wp *WorkerPoolAdapter) WaitAndStopTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return wp.WaitAndStopContext(ctx)
+}
+
+// WaitAndStopContext stops accepting new tasks and blocks until all the queued and running tasks are finished
+// or the context is done. The pool is stopped in both cases, a *ShutdownError reports the tasks which were
+// still queued or running when the context finished.
+func (wp *WorkerPoolAdapter) WaitAndStopContext(ctx context.Context) error {
+	_, err := wp.Drain(ctx)
+	return err
+}
+
+// Drain stops accepting new tasks and executes the queued tasks, including the tasks waiting behind their key,
+// until the context is done. The tasks which never ran are returned, they can be submitted again to another pool
+// using their Func and Params. Scheduled tasks which are not due yet are never run and returned in due order.
+// Tasks waiting for a retry are not retried anymore, they finish with the error of their last attempt
+// and are not returned.
+// If the context finishes first, the queued tasks and the tasks waiting behind their key are returned as well
+// in submission order, together with a *ShutdownError.
+func (wp *WorkerPoolAdapter) Drain(ctx context.Context) ([]*gr_worker.Task, error) {
+	wp.closeQueue()
+	wp.keyedTasks.close()
+	notScheduled := wp.dropScheduledTasks()
+
+	select {
+	case <-wp.taskTracker.wait():
+	case <-ctx.Done():
+		_, running := wp.taskTracker.counts()
+		notExecuted := wp.dropQueuedTasks()
+		wp.Stop()
+		return append(notExecuted, notScheduled...), &ShutdownError{Queued: len(notExecuted), Running: running, Err: ctx.Err()}
+	}
+
+	wp.Stop()
+	workersDone := make(chan struct{})
+	go func() {
+		wp.workers.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+		return notScheduled, nil
+	case <-ctx.Done():
+		return notScheduled, &ShutdownError{Err: ctx.Err()}
+	}
+}
+
+// dropScheduledTasks stops the task scheduler and finishes the futures of the tasks
+// which are not due yet with ErrTaskDropped, or with their last error for the retried ones.
+func (wp *WorkerPoolAdapter) dropScheduledTasks() []*gr_worker.Task {
+	return wp.dropFutures(wp.finishRetries(wp.scheduler.stop()))
+}
+
+// dropQueuedTasks finishes the futures of all the queued tasks with ErrTaskDropped and returns their tasks.
+func (wp *WorkerPoolAdapter) dropQueuedTasks() []*gr_worker.Task {
+	return wp.dropFutures(wp.taskTracker.removeQueued())
+}
+
+// dropFutures finishes the pending futures with ErrTaskDropped, sends them to the dead letter sink
+// and returns their tasks.
+func (wp *WorkerPoolAdapter) dropFutures(futures []*Future) []*gr_worker.Task {
+	dropped := make([]*gr_worker.Task, 0, len(futures))
+	for _, future := range futures {
+		if future.drop(ErrTaskDropped) {
+			wp.counters.dropped.Add(1)
+			wp.sendDeadLetter(future, TASK_DROPPED, ErrTaskDropped)
+			dropped = append(dropped, future.Task())
 		}
 	}
+	return dropped
+}
+
+func (wp *WorkerPoolAdapter) markStopped() {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+	wp.stopped.Store(true)
 }
 
 func (wp *WorkerPoolAdapter) AddTaskIfSpaceAvailable(taskFunc interface{}, params ...interface{}) bool {
-	if wp.IsWorkerPoolStopped() {
-		return false
+	future := newFuture(gr_worker.NewTask(taskFunc, params...))
+	err := wp.writeTask(future, REJECT)
+	if err == ErrQueueFull {
+		wp.reportRejection(future, REJECT)
 	}
+	wp.counters.taskSubmitted(err)
+	return err == nil
+}
 
-	originalTask := gr_worker.NewTask(taskFunc, params...)
-	newTask := gr_worker.NewTask(wp.addConcurrencyDetailsToNewTask, originalTask)
+func (wp *WorkerPoolAdapter) AddTask(taskFunc interface{}, params ...interface{}) bool {
+	_, err := wp.Submit(taskFunc, params...)
+	return err == nil
+}
 
-	wp.startNewWorkerIfRequired()
-	return wp.tasks.WriteValue(newTask)
+// Submit adds a task to the pool and returns its Future. While the queue is full, the rejection policy of the pool
+// is applied, by default it blocks until space is available in the pool.
+func (wp *WorkerPoolAdapter) Submit(taskFunc interface{}, params ...interface{}) (*Future, error) {
+	return wp.submitTask(gr_worker.NewTask(taskFunc, params...))
 }
 
-func (wp *WorkerPoolAdapter) AddTask(taskFunc interface{}, params ...interface{}) bool {
+func (wp *WorkerPoolAdapter) AddTaskWithContext(ctx context.Context, taskFunc interface{}, params ...interface{}) bool {
+	_, err := wp.SubmitWithContext(ctx, taskFunc, params...)
+	return err == nil
+}
+
+// SubmitWithContext adds a task which is dropped if the context expires before its execution.
+// If the first parameter of the task function is a context.Context, a context derived from both
+// the given context and the pool context is passed to it. The deadline of the given context limits
+// the execution of this task only, use it as a per-task timeout next to the pool TaskTimeout.
+func (wp *WorkerPoolAdapter) SubmitWithContext(ctx context.Context, taskFunc interface{}, params ...interface{}) (*Future, error) {
+	return wp.submitTask(gr_worker.NewTaskWithContext(ctx, taskFunc, params...))
+}
+
+func (wp *WorkerPoolAdapter) AddTaskWithPriority(priority int, taskFunc interface{}, params ...interface{}) bool {
+	_, err := wp.SubmitWithPriority(priority, taskFunc, params...)
+	return err == nil
+}
+
+// SubmitWithPriority adds a task which is executed before all the queued tasks with a lower priority.
+// Tasks added without priority have the priority zero.
+func (wp *WorkerPoolAdapter) SubmitWithPriority(priority int, taskFunc interface{}, params ...interface{}) (*Future, error) {
+	future := newFuture(gr_worker.NewTask(taskFunc, params...))
+	future.priority = priority
+	return wp.submitFuture(future)
+}
+
+func (wp *WorkerPoolAdapter) AddTaskWithRetry(policy RetryPolicy, taskFunc interface{}, params ...interface{}) bool {
+	_, err := wp.SubmitWithRetry(policy, taskFunc, params...)
+	return err == nil
+}
+
+// SubmitWithRetry adds a task which is retried with the given policy instead of the retry policy of the pool.
+// The future finishes once the task succeeds or is not retried anymore, with the error of its last attempt.
+func (wp *WorkerPoolAdapter) SubmitWithRetry(policy RetryPolicy, taskFunc interface{}, params ...interface{}) (*Future, error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+
+	future := newFuture(gr_worker.NewTask(taskFunc, params...))
+	future.retryPolicy = &policy
+	return wp.submitFuture(future)
+}
+
+func (wp *WorkerPoolAdapter) AddTaskAfter(delay time.Duration, taskFunc interface{}, params ...interface{}) bool {
+	_, err := wp.SubmitAfter(delay, taskFunc, params...)
+	return err == nil
+}
+
+func (wp *WorkerPoolAdapter) AddTaskAt(at time.Time, taskFunc interface{}, params ...interface{}) bool {
+	_, err := wp.SubmitAt(at, taskFunc, params...)
+	return err == nil
+}
+
+// SubmitAfter schedules a task which is added to the task queue once the delay has passed.
+func (wp *WorkerPoolAdapter) SubmitAfter(delay time.Duration, taskFunc interface{}, params ...interface{}) (*Future, error) {
+	return wp.SubmitAt(time.Now().Add(delay), taskFunc, params...)
+}
+
+// SubmitAt schedules a task which is added to the task queue at the given time. Until then the task is not counted
+// as a queued task and it can be removed with Future.Cancel. Tasks which are not due when the pool stops are dropped.
+func (wp *WorkerPoolAdapter) SubmitAt(at time.Time, taskFunc interface{}, params ...interface{}) (*Future, error) {
 	if wp.IsWorkerPoolStopped() {
-		return false
+		wp.counters.taskSubmitted(ErrWorkerPoolStopped)
+		return nil, ErrWorkerPoolStopped
 	}
 
-	originalTask := gr_worker.NewTask(taskFunc, params...)
-	newTask := gr_worker.NewTask(wp.addConcurrencyDetailsToNewTask, originalTask)
+	future := newFuture(gr_worker.NewTask(taskFunc, params...))
+	if !wp.scheduler.schedule(future, at) {
+		wp.counters.taskSubmitted(ErrWorkerPoolStopped)
+		return nil, ErrWorkerPoolStopped
+	}
+	wp.counters.taskSubmitted(nil)
+	return future, nil
+}
+
+// ScheduledTaskCount returns the number of scheduled tasks which are not due yet.
+func (wp *WorkerPoolAdapter) ScheduledTaskCount() int {
+	return wp.scheduler.len()
+}
+
+func (wp *WorkerPoolAdapter) submitTask(originalTask *gr_worker.Task) (*Future, error) {
+	return wp.submitFuture(newFuture(originalTask))
+}
+
+func (wp *WorkerPoolAdapter) submitFuture(future *Future) (*Future, error) {
+	err := wp.submitWithRejectionPolicy(future)
+	wp.counters.taskSubmitted(err)
+	if err != nil {
+		return nil, err
+	}
+	return future, nil
+}
+
+// writeTask adds the task to the task queue, the rejection policy tells how to proceed while the queue is full.
+func (wp *WorkerPoolAdapter) writeTask(future *Future, policy RejectionPolicy) error {
+	if wp.IsWorkerPoolStopped() {
+		return ErrWorkerPoolStopped
+	}
 
 	wp.startNewWorkerIfRequired()
-	wp.tasks.MustWriteValue(newTask)
-	return true
+	return wp.enqueue(future, policy)
 }
 
-func (wp *WorkerPoolAdapter) addConcurrencyDetailsToNewTask(originalTask *gr_worker.Task) error {
-	wp.idleWorkerCount.Add(-1)
-	err := originalTask.ExecuteTask()
-	wp.idleWorkerCount.Add(1)
-	return err
+// addConcurrencyDetailsToNewTask executes the task of the future, it returns true if the task failed
+// and is going to be retried from a new queue token.
+func (wp *WorkerPoolAdapter) addConcurrencyDetailsToNewTask(future *Future) (bool, error) {
+	state := future.start()
+	switch state {
+	case futureCancelled:
+		wp.taskTracker.remove(future)
+		return false, nil
+	case futureRunning:
+		wp.taskTracker.start(future)
+		defer wp.taskTracker.done()
+		future.attempts.Add(1)
+		wp.counters.queueWait.observe(time.Since(future.enqueuedAt))
+	}
+
+	originalTask := future.Task()
+	if parentCtx := originalTask.Context(); parentCtx != nil && parentCtx.Err() != nil {
+		err := fmt.Errorf("%w: %w", ErrTaskContextExpired, parentCtx.Err())
+		wp.handleTaskError(originalTask, err)
+		if state == futureRunning {
+			wp.counters.taskFinished(err)
+		}
+		future.finish(nil, err)
+		return false, err
+	}
+
+	taskCtx, cancelTaskCtx := wp.newTaskContext(originalTask.Context())
+	defer cancelTaskCtx()
+	future.setCancelFunc(cancelTaskCtx)
+
+	err := wp.runTaskHandler(taskCtx, originalTask)
+	wp.handleTaskError(originalTask, err)
+	if state != futureRunning {
+		return false, err
+	}
+
+	if err != nil {
+		switch wp.retryTask(future, err) {
+		case retryScheduled:
+			wp.counters.retried.Add(1)
+			return true, err
+		case retryExhausted:
+			wp.sendDeadLetter(future, RETRIES_EXHAUSTED, err)
+		case retryDropped:
+			wp.sendDeadLetter(future, TASK_DROPPED, err)
+		default:
+			if isPanic(err) {
+				wp.sendDeadLetter(future, TASK_PANICKED, err)
+			}
+		}
+	}
+	wp.counters.taskFinished(err)
+	future.finish(originalTask.Results(), err)
+	return false, err
 }
 
-func (wp *WorkerPoolAdapter) startNewWorkerIfRequired() {
-	if wp.increaseWorkerCount() {
-		newWorker := wp.createNewWorker()
-		go newWorker.Start()
+func (wp *WorkerPoolAdapter) handleTaskError(task *gr_worker.Task, err error) {
+	if err != nil && wp.errorHandler != nil {
+		wp.errorHandler(task, err)
 	}
 }
 
-func (wp *WorkerPoolAdapter) createNewWorker() worker.Worker {
-	switch wp.strategy {
-	case worker.STANDARD_WORKER:
-		return worker.NewStandardWorker(wp.ctx, wp.tasks, wp.logger, wp.decreaseWorkerCount)
-	case worker.IDEAL_WORKER_TIMEOUT:
-		return worker.NewIdealTimeoutWorker(wp.ctx, wp.idleTimeout, wp.logger, wp.tasks, wp.decreaseWorkerCount)
-	case worker.SINGLE_TASK_WORKER:
-		return worker.NewSingleTaskWorker(wp.ctx, wp.tasks, wp.logger, wp.decreaseWorkerCount)
+// logTaskFailure logs a failed execution of the task with the name of the task function.
+func (wp *WorkerPoolAdapter) logTaskFailure(task *gr_worker.Task, err error) {
+	if err == nil {
+		return
 	}
-	return nil
+
+	var panicErr *gr_worker.PanicError
+	if errors.As(err, &panicErr) {
+		wp.logger.Printf("[PANIC] Function %s panicked: %v\n%s", functionName(task.Func()), panicErr.Value, panicErr.Stack)
+		return
+	}
+	wp.logger.Printf("[ERROR] Function %s return non nil result: %#v", functionName(task.Func()), err)
+}
+
+// newTaskContext derives the context of a task execution, it is cancelled when either the parent context
+// or the pool context is done, or when the task timeout expires.
+func (wp *WorkerPoolAdapter) newTaskContext(parentCtx context.Context) (context.Context, context.CancelFunc) {
+	if parentCtx == nil {
+		parentCtx = wp.ctx
+	}
+
+	taskCtx, cancelCtx := context.WithCancel(parentCtx)
+	stopAfterFunc := context.AfterFunc(wp.ctx, cancelCtx)
+	if wp.taskTimeout <= 0 {
+		return taskCtx, func() {
+			stopAfterFunc()
+			cancelCtx()
+		}
+	}
+
+	taskCtx, cancelTimeout := context.WithTimeout(taskCtx, wp.taskTimeout)
+	return taskCtx, func() {
+		stopAfterFunc()
+		cancelTimeout()
+		cancelCtx()
+	}
+}
+
+func (wp *WorkerPoolAdapter) startNewWorkerIfRequired() bool {
+	poolWorker := wp.increaseWorkerCount()
+	if poolWorker == nil {
+		return false
+	}
+
+	newWorker := wp.createNewWorker(poolWorker)
+	go func() {
+		defer wp.workers.Done()
+		defer poolWorker.cancel()
+		if wp.hooks.OnWorkerStart != nil {
+			wp.hooks.OnWorkerStart()
+		}
+		newWorker.Start()
+	}()
+	return true
+}
+
+func (wp *WorkerPoolAdapter) createNewWorker(poolWorker *poolWorker) worker.Worker {
+	return wp.workerFactory.NewWorker(poolWorker.ctx, wp.tasks, wp.logger, func(workerStatus domain.WorkerStatus) bool {
+		return wp.decreaseWorkerCount(poolWorker, workerStatus)
+	})
 }
 
 //func (wp *WorkerPoolAdapter) canStopWorker(isCtxDone bool) bool {
 //	return isCtxDone || wp.decreaseWorkerCount()
 //}
 
-func (wp *WorkerPoolAdapter) increaseWorkerCount() bool {
+func (wp *WorkerPoolAdapter) increaseWorkerCount() *poolWorker {
 	wp.mutex.Lock()
 	defer wp.mutex.Unlock()
 
 	if wp.IsWorkerPoolStopped() {
-		return false
+		return nil
 	}
-	if wp.activeWorkerCount.Load() >= wp.maxWorkers {
-		return false
+	if wp.activeWorkerCount.Load() >= wp.concurrencyLimit() {
+		return nil
 	}
-	if int(wp.idleWorkerCount.Load()) > len(wp.tasks.Receive()) && wp.activeWorkerCount.Load() >= wp.minWorkers {
-		return false
+	if int(wp.idleWorkerCount.Load()) > wp.queueLength() && wp.activeWorkerCount.Load() >= wp.minWorkers {
+		return nil
 	}
 
 	wp.idleWorkerCount.Add(1)
 	wp.activeWorkerCount.Add(1)
-	return true
+	wp.workers.Add(1)
+	return wp.addPoolWorkerLocked()
 }
 
-func (wp *WorkerPoolAdapter) decreaseWorkerCount(workerStatus domain.WorkerStatus) bool {
+func (wp *WorkerPoolAdapter) decreaseWorkerCount(poolWorker *poolWorker, workerStatus domain.WorkerStatus) (stopped bool) {
+	defer func() {
+		if stopped && wp.hooks.OnWorkerStop != nil {
+			wp.hooks.OnWorkerStop(workerStatus)
+		}
+	}()
+
 	wp.mutex.Lock()
 	defer wp.mutex.Unlock()
 
 	switch workerStatus {
 	case domain.TIMEOUT:
-		if wp.idleWorkerCount.Load() <= 0 || (wp.activeWorkerCount.Load() <= wp.minWorkers) {
+		if wp.idleWorkerCount.Load() <= 0 || (wp.activeWorkerCount.Load() <= wp.minWorkers) || wp.paused.Load() {
 			return false
 		}
 	}
 
 	wp.idleWorkerCount.Add(-1)
 	wp.activeWorkerCount.Add(-1)
+	delete(wp.poolWorkers, poolWorker)
 	return true
 }
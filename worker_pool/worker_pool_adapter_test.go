@@ -1,11 +1,20 @@
 package worker_pool
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	gr_variable "github.com/vd09/gr-variable"
+	"github.com/vd09/gr_worker"
+	"github.com/vd09/gr_worker/logger"
+	"github.com/vd09/gr_worker/worker"
 )
 
 func TestWorkerPoolAdapter_AddTask_IsTaskAdded(t *testing.T) {
@@ -139,3 +148,158 @@ func TestWorkerPoolAdapter_WaitAndStop(t *testing.T) {
 		t.Error("worker pool not stopped after WaitAndStop")
 	}
 }
+
+func TestWorkerPoolAdapter_PanicRecovery(t *testing.T) {
+	var panics atomic.Int32
+	wp, err := NewWorkerPoolAdapter(
+		WithMinWorkers(1),
+		WithMaxWorkers(1),
+		WithMaxTasks(2),
+		WithLogger(logger.Discard),
+		WithErrorHandler(func(task *gr_worker.Task, err error) {
+			var panicErr *gr_worker.PanicError
+			if errors.As(err, &panicErr) {
+				panics.Add(1)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	var executed atomic.Bool
+	wp.AddTask(func() { panic("boom") })
+	wp.AddTask(func() { executed.Store(true) })
+	wp.WaitAndStop()
+
+	if panics.Load() != 1 {
+		t.Errorf("panic is not reported to the error handler; reported: %d", panics.Load())
+	}
+	if !executed.Load() {
+		t.Error("task after the panicking task is not executed")
+	}
+	if wp.activeWorkerCount.Load() != 0 || wp.idleWorkerCount.Load() != 0 {
+		t.Errorf("worker counts are not restored; active: %d, idle: %d",
+			wp.activeWorkerCount.Load(), wp.idleWorkerCount.Load())
+	}
+}
+
+func failingTask() error {
+	return errTemporary
+}
+
+func TestWorkerPoolAdapter_LogsTaskFunction(t *testing.T) {
+	var mutex sync.Mutex
+	var logs []string
+	wp, err := NewWorkerPoolAdapter(WithMaxWorkers(1), WithLogger(logger.Func(func(format string, v ...interface{}) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		logs = append(logs, fmt.Sprintf(format, v...))
+	})))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	wp.AddTask(failingTask)
+	wp.AddTask(func() { panic("boom") })
+	wp.WaitAndStop()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(logs) != 2 {
+		t.Fatalf("unexpected logs: %q", logs)
+	}
+	if !strings.HasPrefix(logs[0], "[ERROR] Function github.com/vd09/gr_worker/worker_pool.failingTask ") {
+		t.Errorf("failure is not logged with the task function: %q", logs[0])
+	}
+	if !strings.HasPrefix(logs[1], "[PANIC] Function github.com/vd09/gr_worker/worker_pool.TestWorkerPoolAdapter_LogsTaskFunction.") {
+		t.Errorf("panic is not logged with the task function: %q", logs[1])
+	}
+}
+
+func TestWorkerPoolAdapter_WaitAndStopTimeout(t *testing.T) {
+	wp, err := NewWorkerPool(
+		WithMaxWorkers(1),
+		WithMaxTasks(2),
+	)
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	blockingFunction := func() { <-release }
+	wp.AddTask(blockingFunction)
+	wp.AddTask(blockingFunction)
+
+	err = wp.WaitAndStopTimeout(50 * time.Millisecond)
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("expected *ShutdownError, got: %#v", err)
+	}
+	if shutdownErr.Queued != 1 || shutdownErr.Running != 1 {
+		t.Errorf("unexpected pending tasks; queued: %d, running: %d", shutdownErr.Queued, shutdownErr.Running)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+	if !wp.IsWorkerPoolStopped() {
+		t.Error("worker pool not stopped after WaitAndStopTimeout")
+	}
+	if wp.AddTask(blockingFunction) {
+		t.Error("task added to a stopped worker pool")
+	}
+}
+
+func TestWorkerPoolAdapter_WaitAndStopContext(t *testing.T) {
+	wp, err := NewWorkerPool(
+		WithMinWorkers(1),
+		WithMaxWorkers(2),
+		WithMaxTasks(4),
+		WithWorkerStrategy(worker.IDEAL_WORKER_TIMEOUT),
+	)
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	var count atomic.Int32
+	for i := 0; i < 4; i++ {
+		wp.AddTask(func() {
+			time.Sleep(10 * time.Millisecond)
+			count.Add(1)
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := wp.WaitAndStopContext(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if count.Load() != 4 {
+		t.Errorf("WaitAndStopContext returned before all tasks completed; completed: %d", count.Load())
+	}
+}
+
+func TestWorkerPoolAdapter_WithWorkerFactory(t *testing.T) {
+	var created atomic.Int32
+	factory := worker.WorkerFactoryFunc(func(ctx context.Context, tasks gr_variable.GrChannel[*gr_worker.Task],
+		logger logger.Logger, isEligibleToStop worker.IsEligibleToStopFunc) worker.Worker {
+		created.Add(1)
+		return worker.NewStandardWorker(ctx, tasks, logger, isEligibleToStop)
+	})
+
+	wp, err := NewWorkerPool(WithMaxWorkers(2), WithWorkerFactory(factory))
+	if err != nil {
+		t.Fatalf("error creating worker pool: %v", err)
+	}
+
+	future, _ := wp.Submit(func() int { return 1 })
+	if _, err := future.Result(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	wp.WaitAndStop()
+
+	if created.Load() != 2 {
+		t.Errorf("unexpected workers created by the factory, got: %d, want: 2", created.Load())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/vd09/gr_worker/logger"
 	"github.com/vd09/gr_worker/worker"
 )
 
@@ -30,6 +31,39 @@ func WithMaxTasks(maxTasks int32) Option {
 	}
 }
 
+// MaxTasksPerKey allows to change the number of tasks which can wait behind the running task of a key,
+// zero keeps the default
+func WithMaxTasksPerKey(maxTasksPerKey int32) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.maxTasksPerKey = maxTasksPerKey
+	}
+}
+
+// RejectionPolicy allows to change what happens to a task submitted while the task queue is full,
+// AddTaskIfSpaceAvailable always rejects it
+func WithRejectionPolicy(policy RejectionPolicy) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.rejectionPolicy = policy
+	}
+}
+
+// BlockTimeout allows to change the time a task waits for space in the queue with BLOCK_WITH_TIMEOUT
+func WithBlockTimeout(timeout time.Duration) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.blockTimeout = timeout
+	}
+}
+
+// Tenant allows to change the weight and the max queued tasks of a tenant of the pool
+func WithTenant(tenant string, config TenantConfig) Option {
+	return func(wp *WorkerPoolAdapter) {
+		if wp.tenants == nil {
+			wp.tenants = make(map[string]TenantConfig)
+		}
+		wp.tenants[tenant] = config
+	}
+}
+
 // IdleTimeout allows to change the idle timeout for a worker pool
 func WithIdleTimeout(idleTimeout time.Duration) Option {
 	return func(wp *WorkerPoolAdapter) {
@@ -37,6 +71,38 @@ func WithIdleTimeout(idleTimeout time.Duration) Option {
 	}
 }
 
+// TaskTimeout limits the execution time of every task, the task context is cancelled once it expires.
+// A single task is limited by the deadline of the context given to SubmitWithContext, the earlier one applies
+func WithTaskTimeout(taskTimeout time.Duration) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.taskTimeout = taskTimeout
+	}
+}
+
+// PriorityAging raises the priority of a queued task by one for every interval it waits, so low priority tasks
+// are never starved by a stream of high priority tasks
+func WithPriorityAging(interval time.Duration) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.agingInterval = interval
+	}
+}
+
+// RetryPolicy configures the retries of the tasks which return an error, tasks submitted with their own policy
+// are retried with it instead
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.retryPolicy = &policy
+	}
+}
+
+// AdaptiveConcurrency adjusts the number of tasks executed concurrently between the min and the max workers
+// from the latency and the error rate of the tasks
+func WithAdaptiveConcurrency(config AdaptiveConcurrency) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.adaptive = &config
+	}
+}
+
 // Worker strategy allows to change the strategy used to resize the pool
 func WithWorkerStrategy(strategy worker.WorkerStrategy) Option {
 	return func(wp *WorkerPoolAdapter) {
@@ -44,9 +110,53 @@ func WithWorkerStrategy(strategy worker.WorkerStrategy) Option {
 	}
 }
 
+// WorkerFactory allows to use custom workers, it takes precedence over the worker strategy
+func WithWorkerFactory(factory worker.WorkerFactory) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.workerFactory = factory
+	}
+}
+
 // Context configures a parent context on a worker pool to stop all workers when it is cancelled
 func WithContext(parentCtx context.Context) Option {
 	return func(wp *WorkerPoolAdapter) {
 		wp.ctx, wp.cancelCtx = context.WithCancel(parentCtx)
 	}
 }
+
+// Logger allows to change the logger used by the workers to report failed tasks
+func WithLogger(logger logger.Logger) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.logger = logger
+	}
+}
+
+// ErrorHandler configures a sink which receives every task that returned an error or panicked
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.errorHandler = handler
+	}
+}
+
+// DeadLetterSink configures a sink which receives the tasks that exhausted their retries, panicked
+// or were dropped while stopping the pool
+func WithDeadLetterSink(sink DeadLetterSink) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.deadLetterSink = sink
+	}
+}
+
+// Middleware wraps the execution of every task, the middlewares are applied in order so the first one is
+// the outermost. The option can be used multiple times
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.middlewares = append(wp.middlewares, middlewares...)
+	}
+}
+
+// Hooks configures the callbacks called on the lifecycle events of the workers and the tasks
+func WithHooks(hooks Hooks) Option {
+	return func(wp *WorkerPoolAdapter) {
+		wp.hooks = hooks
+	}
+}
@@ -1,12 +1,22 @@
 package worker_pool
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/vd09/gr_worker/worker"
+)
 
 var (
-	ErrMaxWorkers  = errors.New("max workers can't be less than one")
-	ErrMinWorkers  = errors.New("min workers is greater than max workers")
-	ErrMaxTasks    = errors.New("max tasks can't be less than one")
-	ErrIdleTimeout = errors.New("max tasks can't be less than zero")
+	ErrMaxWorkers   = errors.New("max workers can't be less than one")
+	ErrMinWorkers   = errors.New("min workers is greater than max workers")
+	ErrMaxTasks     = errors.New("max tasks can't be less than one")
+	ErrIdleTimeout  = errors.New("max tasks can't be less than zero")
+	ErrTaskTimeout  = errors.New("task timeout can't be less than zero")
+	ErrAging        = errors.New("priority aging interval can't be less than zero")
+	ErrStrategy     = errors.New("unknown worker strategy")
+	ErrTasksPerKey  = errors.New("max tasks per key can't be less than zero")
+	ErrRejection    = errors.New("unknown rejection policy")
+	ErrBlockTimeout = errors.New("block timeout must be greater than zero with BLOCK_WITH_TIMEOUT")
 )
 
 func (wp *WorkerPoolAdapter) validateMaxWorkers() error {
@@ -30,6 +40,32 @@ func (wp *WorkerPoolAdapter) validateMaxTasks() error {
 	return nil
 }
 
+func (wp *WorkerPoolAdapter) validateMaxTasksPerKey() error {
+	if wp.maxTasksPerKey < 0 {
+		return ErrTasksPerKey
+	}
+	return nil
+}
+
+func (wp *WorkerPoolAdapter) validateRejectionPolicy() error {
+	if !wp.rejectionPolicy.isValid() {
+		return ErrRejection
+	}
+	if wp.rejectionPolicy == BLOCK_WITH_TIMEOUT && wp.blockTimeout <= 0 {
+		return ErrBlockTimeout
+	}
+	return nil
+}
+
+func (wp *WorkerPoolAdapter) validateTenants() error {
+	for _, config := range wp.tenants {
+		if err := config.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (wp *WorkerPoolAdapter) validateIdleTimeout() error {
 	if wp.idleTimeout < 0 {
 		return ErrIdleTimeout
@@ -37,6 +73,44 @@ func (wp *WorkerPoolAdapter) validateIdleTimeout() error {
 	return nil
 }
 
+func (wp *WorkerPoolAdapter) validateTaskTimeout() error {
+	if wp.taskTimeout < 0 {
+		return ErrTaskTimeout
+	}
+	return nil
+}
+
+func (wp *WorkerPoolAdapter) validateAgingInterval() error {
+	if wp.agingInterval < 0 {
+		return ErrAging
+	}
+	return nil
+}
+
+func (wp *WorkerPoolAdapter) validateWorkerStrategy() error {
+	if wp.workerFactory != nil {
+		return nil
+	}
+	if _, ok := worker.NewWorkerFactory(wp.strategy, wp.idleTimeout); !ok {
+		return ErrStrategy
+	}
+	return nil
+}
+
+func (wp *WorkerPoolAdapter) validateRetryPolicy() error {
+	if wp.retryPolicy == nil {
+		return nil
+	}
+	return wp.retryPolicy.validate()
+}
+
+func (wp *WorkerPoolAdapter) validateAdaptiveConcurrency() error {
+	if wp.adaptive == nil {
+		return nil
+	}
+	return wp.adaptive.validate()
+}
+
 func (wp *WorkerPoolAdapter) ValidateWorkerPool() error {
 	if err := wp.validateMaxWorkers(); err != nil {
 		return err
@@ -47,8 +121,32 @@ func (wp *WorkerPoolAdapter) ValidateWorkerPool() error {
 	if err := wp.validateMaxTasks(); err != nil {
 		return err
 	}
+	if err := wp.validateMaxTasksPerKey(); err != nil {
+		return err
+	}
+	if err := wp.validateRejectionPolicy(); err != nil {
+		return err
+	}
+	if err := wp.validateTenants(); err != nil {
+		return err
+	}
 	if err := wp.validateIdleTimeout(); err != nil {
 		return err
 	}
+	if err := wp.validateTaskTimeout(); err != nil {
+		return err
+	}
+	if err := wp.validateAgingInterval(); err != nil {
+		return err
+	}
+	if err := wp.validateWorkerStrategy(); err != nil {
+		return err
+	}
+	if err := wp.validateRetryPolicy(); err != nil {
+		return err
+	}
+	if err := wp.validateAdaptiveConcurrency(); err != nil {
+		return err
+	}
 	return nil
 }
@@ -3,6 +3,8 @@ package worker_pool
 import (
 	"testing"
 	"time"
+
+	"github.com/vd09/gr_worker/worker"
 )
 
 func TestWorkerPoolAdapter_ValidateWorkerPool(t *testing.T) {
@@ -36,3 +38,15 @@ func TestWorkerPoolAdapter_ValidateWorkerPool(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkerPoolAdapter_ValidateWorkerStrategy(t *testing.T) {
+	if _, err := NewWorkerPool(WithWorkerStrategy(worker.WorkerStrategy(42))); err != ErrStrategy {
+		t.Errorf("unexpected error, got: %v, want: %v", err, ErrStrategy)
+	}
+
+	wp, err := NewWorkerPool(WithWorkerStrategy(worker.WorkerStrategy(42)), WithWorkerFactory(worker.StandardWorkerFactory))
+	if err != nil {
+		t.Fatalf("unexpected error with a worker factory: %v", err)
+	}
+	wp.Stop()
+}